@@ -0,0 +1,209 @@
+package ehttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AccessLogFormat is the format of the access logs. See WithAccessLog.
+type AccessLogFormat int
+
+// Access log formats.
+const (
+	CommonLog   AccessLogFormat = iota // Apache Common Log Format.
+	CombinedLog                        // Apache Combined Log Format.
+	JSONLog                            // One JSON object per line.
+)
+
+// accessEntry describes a served request.
+type accessEntry struct {
+	req     *http.Request
+	start   time.Time
+	latency time.Duration
+	ttfb    time.Duration // Time to first byte. 0 if nothing written.
+	status  int
+	bytes   int64
+	err     error
+}
+
+// WithAccessLog logs every request to the given writer in the given format:
+// method, path, status, bytes written, latency, remote address, and for JSONLog,
+// the time to first byte and the returned error.
+func WithAccessLog(w io.Writer, format AccessLogFormat) Option {
+	var mu sync.Mutex
+	return func(sm *ServeMux) {
+		sm.accessLog = func(e *accessEntry) {
+			var buf bytes.Buffer
+			switch format {
+			case CombinedLog:
+				writeCommonLog(&buf, e)
+				fmt.Fprintf(&buf, " %q %q", orDash(e.req.Referer()), orDash(e.req.UserAgent()))
+			case JSONLog:
+				writeJSONLog(&buf, e)
+			default:
+				writeCommonLog(&buf, e)
+			}
+			buf.WriteByte('\n')
+			mu.Lock()
+			defer mu.Unlock()
+			_, _ = w.Write(buf.Bytes())
+		}
+	}
+}
+
+// WithAccessLogHandler logs every request to the given slog handler.
+func WithAccessLogHandler(h slog.Handler) Option {
+	logger := slog.New(h)
+	return func(sm *ServeMux) {
+		sm.accessLog = func(e *accessEntry) {
+			attrs := append(requestAttrs(e.req),
+				slog.Int("status", e.status),
+				slog.Int64("bytes", e.bytes),
+				slog.Duration("latency", e.latency),
+				slog.Duration("ttfb", e.ttfb),
+				slog.String("remote_addr", e.req.RemoteAddr),
+			)
+			if e.err != nil {
+				attrs = append(attrs, slog.String("error", e.err.Error()))
+			}
+			logger.LogAttrs(context.Background(), slog.LevelInfo, "HTTP request", attrs...)
+		}
+	}
+}
+
+// accessRecord is the request and error handled while serving a request logged by ServeAccessLog.
+// Stored in the request context.
+type accessRecord struct {
+	req *http.Request
+	err error
+}
+
+// accessRecordKey is the context key of the accessRecord.
+type accessRecordKey struct{}
+
+// ServeAccessLog serves the request with the given handler and logs it once served, if the access logs are enabled.
+// The error handled while serving (i.e. by MWError) is part of the log, so each request is logged once,
+// including the ones served by standard handlers and the recovered panics.
+// Should not be manually called. Exposed to be accessed from adaptor subpackages.
+func (sm *ServeMux) ServeAccessLog(w http.ResponseWriter, req *http.Request, handler http.Handler) {
+	if sm.accessLog == nil || req == nil || req.Context().Value(accessRecordKey{}) != nil {
+		handler.ServeHTTP(w, req)
+		return
+	}
+	start := time.Now()
+	ww := NewResponseWriter(w)
+	rec := &accessRecord{}
+	req = req.WithContext(context.WithValue(req.Context(), accessRecordKey{}, rec))
+	handler.ServeHTTP(ww, req)
+	if rec.req != nil {
+		req = rec.req
+	}
+	sm.logAccess(ww, req, start, rec.err)
+}
+
+// recordAccess records the request and the handled error for the access log.
+// If the request is not served by ServeAccessLog, the request is logged right away.
+func (sm *ServeMux) recordAccess(w ResponseWriter, req *http.Request, start time.Time, err error) {
+	if rec, ok := req.Context().Value(accessRecordKey{}).(*accessRecord); ok {
+		rec.req, rec.err = req, err
+		return
+	}
+	sm.logAccess(w, req, start, err)
+}
+
+// logAccess logs the served request, if enabled.
+func (sm *ServeMux) logAccess(w ResponseWriter, req *http.Request, start time.Time, err error) {
+	if sm.accessLog == nil {
+		return
+	}
+	e := &accessEntry{
+		req:     req,
+		start:   start,
+		latency: time.Since(start),
+		status:  w.Code(),
+		bytes:   w.BytesWritten(),
+		err:     err,
+	}
+	if t := w.FirstByteAt(); !t.IsZero() {
+		e.ttfb = t.Sub(start)
+	}
+	if e.status == 0 {
+		e.status = http.StatusOK
+	}
+	sm.accessLog(e)
+}
+
+// writeCommonLog writes the entry in the Apache Common Log Format.
+func writeCommonLog(buf *bytes.Buffer, e *accessEntry) {
+	host, _, err := net.SplitHostPort(e.req.RemoteAddr)
+	if err != nil {
+		host = e.req.RemoteAddr
+	}
+	user := "-"
+	if e.req.URL != nil && e.req.URL.User != nil && e.req.URL.User.Username() != "" {
+		user = e.req.URL.User.Username()
+	} else if name, _, ok := e.req.BasicAuth(); ok && name != "" {
+		user = name
+	}
+	size := "-"
+	if e.bytes > 0 {
+		size = strconv.FormatInt(e.bytes, 10)
+	}
+	uri := e.req.RequestURI
+	if uri == "" && e.req.URL != nil {
+		uri = e.req.URL.RequestURI()
+	}
+	fmt.Fprintf(buf, "%s - %s [%s] \"%s %s %s\" %d %s",
+		orDash(host), user, e.start.Format("02/Jan/2006:15:04:05 -0700"),
+		e.req.Method, uri, e.req.Proto, e.status, size)
+}
+
+// writeJSONLog writes the entry as a JSON object.
+func writeJSONLog(buf *bytes.Buffer, e *accessEntry) {
+	entry := struct {
+		Time       time.Time `json:"time"`
+		Method     string    `json:"method"`
+		Path       string    `json:"path"`
+		Status     int       `json:"status"`
+		Bytes      int64     `json:"bytes"`
+		LatencyMS  float64   `json:"latency_ms"`
+		TTFBMS     float64   `json:"ttfb_ms"`
+		RemoteAddr string    `json:"remote_addr"`
+		RequestID  string    `json:"request_id,omitempty"`
+		Error      string    `json:"error,omitempty"`
+	}{
+		Time:       e.start,
+		Method:     e.req.Method,
+		Status:     e.status,
+		Bytes:      e.bytes,
+		LatencyMS:  float64(e.latency) / float64(time.Millisecond),
+		TTFBMS:     float64(e.ttfb) / float64(time.Millisecond),
+		RemoteAddr: e.req.RemoteAddr,
+		RequestID:  requestIDFromRequest(e.req),
+	}
+	if e.req.URL != nil {
+		entry.Path = e.req.URL.Path
+	}
+	if e.err != nil {
+		entry.Error = e.err.Error()
+	}
+	_ = json.NewEncoder(buf).Encode(entry)
+	buf.Truncate(buf.Len() - 1) // Trim the encoder newline.
+}
+
+// orDash returns "-" for empty strings, as used in the Apache log formats.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,146 @@
+package ehttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func newAccessLogTestMux(opts ...Option) *ServeMux {
+	mux := New(opts...)
+	mux.Get("/hello", func(w http.ResponseWriter, req *http.Request) error {
+		_, err := fmt.Fprint(w, "hello")
+		return err
+	})
+	mux.Get("/fail", func(w http.ResponseWriter, req *http.Request) error {
+		return NewErrorf(http.StatusTeapot, "fail")
+	})
+	return mux
+}
+
+func serveAccessLogTest(mux *ServeMux, path string) {
+	req := httptest.NewRequest("GET", path, nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("Referer", "http://example.com/")
+	req.Header.Set("User-Agent", "test-agent")
+	req.SetBasicAuth("bob", "secret")
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestAccessLogApache(t *testing.T) {
+	for _, tc := range []struct {
+		format AccessLogFormat
+		suffix string
+	}{
+		{format: CommonLog},
+		{format: CombinedLog, suffix: ` "http://example.com/" "test-agent"`},
+	} {
+		buf := bytes.NewBuffer(nil)
+		mux := newAccessLogTestMux(WithAccessLog(buf, tc.format))
+		serveAccessLogTest(mux, "/hello?a=b")
+		serveAccessLogTest(mux, "/fail")
+		serveAccessLogTest(mux, "/unknown")
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		assertInt(t, 3, len(lines))
+		for i, expect := range []string{
+			`"GET /hello?a=b HTTP/1.1" 200 5`,
+			`"GET /fail HTTP/1.1" 418 20`,
+			`"GET /unknown HTTP/1.1" 404 25`,
+		} {
+			re := regexp.MustCompile(`^192\.0\.2\.1 - bob \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] ` + regexp.QuoteMeta(expect+tc.suffix) + `$`)
+			if !re.MatchString(lines[i]) {
+				t.Errorf("Unexpected access log line.\nExpect:\t%s\nGot:\t%s", re, lines[i])
+			}
+		}
+	}
+}
+
+func TestAccessLogJSON(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	mux := newAccessLogTestMux(WithAccessLog(buf, JSONLog), WithRequestID())
+	serveAccessLogTest(mux, "/fail")
+
+	var entry struct {
+		Method     string  `json:"method"`
+		Path       string  `json:"path"`
+		Status     int     `json:"status"`
+		Bytes      int64   `json:"bytes"`
+		LatencyMS  float64 `json:"latency_ms"`
+		RemoteAddr string  `json:"remote_addr"`
+		RequestID  string  `json:"request_id"`
+		Error      string  `json:"error"`
+	}
+	if !strings.HasSuffix(buf.String(), "}\n") || strings.Count(buf.String(), "\n") != 1 {
+		t.Fatalf("Expected a single JSON line, got: %q", buf)
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Error parsing access log: %s", err)
+	}
+	assertString(t, "GET", entry.Method)
+	assertString(t, "/fail", entry.Path)
+	assertInt(t, http.StatusTeapot, entry.Status)
+	assertInt(t, len(`{"errors":["fail"],"request_id":""}`)+len(entry.RequestID)+1, int(entry.Bytes))
+	assertString(t, "192.0.2.1:1234", entry.RemoteAddr)
+	assertString(t, "fail", entry.Error)
+	assertInt(t, 32, len(entry.RequestID))
+	if entry.LatencyMS < 0 {
+		t.Errorf("Unexpected latency: %f", entry.LatencyMS)
+	}
+}
+
+func TestAccessLogHandler(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	mux := newAccessLogTestMux(WithAccessLogHandler(slog.NewTextHandler(buf, nil)))
+	serveAccessLogTest(mux, "/hello")
+	serveAccessLogTest(mux, "/fail")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assertInt(t, 2, len(lines))
+	for i, attrs := range [][]string{
+		{`msg="HTTP request"`, "method=GET", "path=/hello", "pattern=\"GET /hello\"", "status=200", "bytes=5", "latency=", "remote_addr=192.0.2.1:1234"},
+		{"path=/fail", "status=418", "bytes=20", "error=fail"},
+	} {
+		for _, attr := range attrs {
+			if !strings.Contains(lines[i], attr) {
+				t.Errorf("%s not found in access log: %s", attr, lines[i])
+			}
+		}
+	}
+	if strings.Contains(lines[0], "error=") {
+		t.Errorf("Unexpected error in access log: %s", lines[0])
+	}
+}
+
+func TestAccessLogStandardHandler(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	mux := newAccessLogTestMux(WithAccessLog(buf, CommonLog))
+	mux.ServeMux.HandleFunc("/std", func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, "std")
+	})
+	serveAccessLogTest(mux, "/std")
+	serveAccessLogTest(mux, "/hello")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assertInt(t, 2, len(lines))
+	for i, expect := range []string{`"GET /std HTTP/1.1" 200 3`, `"GET /hello HTTP/1.1" 200 5`} {
+		if !strings.HasSuffix(lines[i], expect) {
+			t.Errorf("Unexpected access log line.\nExpect:\t%s\nGot:\t%s", expect, lines[i])
+		}
+	}
+
+	// The handlers used without the mux ServeHTTP still log their requests.
+	buf.Reset()
+	mux.HandlerFunc(func(w http.ResponseWriter, req *http.Request) error {
+		return NotFound
+	}).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/direct", nil))
+	if !strings.Contains(buf.String(), `"GET /direct HTTP/1.1" 404`) || strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("Unexpected access log: %s", buf)
+	}
+}
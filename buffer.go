@@ -0,0 +1,216 @@
+package ehttp
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// bufferedResponseWriter implements ehttp.ResponseWriter and holds the status, headers and body
+// until committed, so the response can be discarded if the handler returns an error.
+// Once the buffer limit is reached, the buffered response is committed and the writer falls back to streaming.
+// It only implements http.ResponseWriter, the optional interfaces are added by wrapBufferedResponseWriter.
+type bufferedResponseWriter struct {
+	w         ResponseWriter // Underlying writer.
+	limit     int            // Maximum number of bytes to buffer.
+	header    http.Header    // Buffered headers.
+	code      int            // Buffered http status code.
+	buf       bytes.Buffer   // Buffered body.
+	streaming bool           // Flag to know whether or not the response has been committed.
+}
+
+// newBufferedResponseWriter instantiates a new bufferedResponseWriter.
+// The headers already set on the underlying writer are kept.
+func newBufferedResponseWriter(w ResponseWriter, limit int) *bufferedResponseWriter {
+	return &bufferedResponseWriter{
+		w:      w,
+		limit:  limit,
+		header: w.Header().Clone(),
+	}
+}
+
+// Header returns the buffered headers, or the underlying ones once streaming.
+func (w *bufferedResponseWriter) Header() http.Header {
+	if w.streaming {
+		return w.w.Header()
+	}
+	return w.header
+}
+
+// Code returns the http status code set by the handler.
+func (w *bufferedResponseWriter) Code() int {
+	if w.streaming {
+		return w.w.Code()
+	}
+	return w.code
+}
+
+// BytesWritten returns the number of body bytes sent to the underlying writer.
+// The buffered bytes are not accounted for until committed.
+func (w *bufferedResponseWriter) BytesWritten() int64 {
+	return w.w.BytesWritten()
+}
+
+// HeaderWrittenAt returns the time the headers were sent to the underlying writer.
+func (w *bufferedResponseWriter) HeaderWrittenAt() time.Time {
+	return w.w.HeaderWrittenAt()
+}
+
+// FirstByteAt returns the time the first body byte was sent to the underlying writer.
+func (w *bufferedResponseWriter) FirstByteAt() time.Time {
+	return w.w.FirstByteAt()
+}
+
+// Written returns whether or not the headers have been sent to the underlying writer.
+func (w *bufferedResponseWriter) Written() bool {
+	return w.w.Written()
+}
+
+// WriteHeader buffers the http status code. Only the first one is kept.
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	if w.streaming {
+		w.w.WriteHeader(code)
+		return
+	}
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+// Write buffers the data. Commits the response and streams if the buffer limit is reached.
+// Following the net/http behavior, if no http status code has been set, assume http.StatusOK.
+func (w *bufferedResponseWriter) Write(buf []byte) (int, error) {
+	if !w.streaming {
+		if w.code == 0 {
+			w.code = http.StatusOK
+		}
+		if w.buf.Len()+len(buf) <= w.limit {
+			return w.buf.Write(buf)
+		}
+		if err := w.commit(); err != nil {
+			return 0, err
+		}
+	}
+	return w.w.Write(buf)
+}
+
+// FlushError commits the response and flushes the underlying writer, returning an error matching
+// http.ErrNotSupported if not supported. Used by http.ResponseController, so the buffered response is sent
+// before flushing even when the underlying flusher is only reachable with Unwrap.
+func (w *bufferedResponseWriter) FlushError() error {
+	if err := w.commit(); err != nil {
+		return err
+	}
+	return http.NewResponseController(w.w).Flush()
+}
+
+// Unwrap returns the underlying writer. Used by http.ResponseController.
+func (w *bufferedResponseWriter) Unwrap() http.ResponseWriter {
+	return w.w
+}
+
+// commit sends the buffered headers, status code and body to the underlying writer and switches to streaming.
+// No-op if already streaming.
+func (w *bufferedResponseWriter) commit() error {
+	if w.streaming {
+		return nil
+	}
+	w.streaming = true
+
+	header := w.w.Header()
+	for k := range header {
+		if _, ok := w.header[k]; !ok {
+			delete(header, k)
+		}
+	}
+	for k, v := range w.header {
+		header[k] = v
+	}
+	if w.code != 0 {
+		w.w.WriteHeader(w.code)
+	}
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.w.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// bufferedFlusher exposes the underlying net/http.Flusher interface.
+type bufferedFlusher struct{ w *bufferedResponseWriter }
+
+// Flush commits the response and flushes the underlying writer.
+func (f bufferedFlusher) Flush() {
+	_ = f.w.FlushError()
+}
+
+// bufferedHijacker exposes the underlying net/http.Hijacker interface.
+type bufferedHijacker struct{ w *bufferedResponseWriter }
+
+// Hijack commits the response and hijacks the underlying connection.
+// The response is not buffered anymore, so it can't be discarded.
+func (h bufferedHijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if err := h.w.commit(); err != nil {
+		return nil, nil, err
+	}
+	return h.w.w.(http.Hijacker).Hijack()
+}
+
+// bufferedReaderFrom exposes the underlying io.ReaderFrom interface.
+type bufferedReaderFrom struct{ w *bufferedResponseWriter }
+
+// ReadFrom buffers the data read from src, see Write. Once streaming, wraps the underlying ReadFrom.
+func (r bufferedReaderFrom) ReadFrom(src io.Reader) (int64, error) {
+	if r.w.streaming {
+		return r.w.w.(io.ReaderFrom).ReadFrom(src)
+	}
+	return io.Copy(struct{ io.Writer }{r.w}, src)
+}
+
+// bufferedPusher exposes the underlying net/http.Pusher interface.
+type bufferedPusher struct{ w *bufferedResponseWriter }
+
+// Push wraps the underlying Push.
+func (p bufferedPusher) Push(target string, opts *http.PushOptions) error {
+	return p.w.w.(http.Pusher).Push(target, opts)
+}
+
+// bufferedCloseNotifier exposes the underlying net/http.CloseNotifier interface.
+type bufferedCloseNotifier struct{ w *bufferedResponseWriter }
+
+// CloseNotify wraps the underlying CloseNotify.
+func (c bufferedCloseNotifier) CloseNotify() <-chan bool {
+	return c.w.w.(http.CloseNotifier).CloseNotify()
+}
+
+// bufferedStringWriter exposes the underlying io.StringWriter interface.
+type bufferedStringWriter struct{ w *bufferedResponseWriter }
+
+// WriteString buffers the string, see Write. Once streaming, wraps the underlying WriteString.
+func (s bufferedStringWriter) WriteString(str string) (int, error) {
+	if s.w.streaming {
+		return s.w.w.(io.StringWriter).WriteString(str)
+	}
+	return s.w.Write([]byte(str))
+}
+
+// Buffered wraps the handler so its response (status, headers and body) is buffered up to limit bytes
+// until the handler returns. When the handler returns an error, the buffered response is discarded
+// so a clean error response can be sent instead.
+// Once the limit is reached, the response is sent and the handler streams the rest of it.
+// Errors returned after that are handled as errors after sending the headers.
+// The writer given to the handler implements the same optional interfaces as the underlying one (see NewResponseWriter):
+// flushing or hijacking commits the response.
+func Buffered(limit int, handler HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		bw := newBufferedResponseWriter(NewResponseWriter(w), limit)
+		if err := handler(wrapBufferedResponseWriter(bw), req); err != nil {
+			return err
+		}
+		return bw.commit()
+	}
+}
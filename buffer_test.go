@@ -0,0 +1,109 @@
+package ehttp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBufferedError(t *testing.T) {
+	mux := New(WithBuffering(1024), WithErrorEncoder(EncodeText))
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
+		w.Header().Set("X-Partial", "true")
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, "partial")
+		return NewErrorf(http.StatusTeapot, "fail")
+	})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assertInt(t, http.StatusTeapot, rec.Code)
+	assertString(t, "fail", rec.Body.String())
+	assertString(t, "", rec.Header().Get("X-Partial"))
+}
+
+func TestBufferedSuccess(t *testing.T) {
+	mux := New()
+	mux.HandleFunc("/", Buffered(1024, func(w http.ResponseWriter, req *http.Request) error {
+		w.Header().Set("X-Custom", "true")
+		w.Header().Del("X-Removed")
+		w.WriteHeader(http.StatusAccepted)
+		w.WriteHeader(http.StatusConflict)
+		if expect, got := http.StatusAccepted, w.(ResponseWriter).Code(); expect != got {
+			return fmt.Errorf("unexpected buffered code: %d", got)
+		}
+		_, _ = io.WriteString(w, "hello ")
+		_, err := w.Write([]byte("world"))
+		return err
+	}))
+
+	rec := httptest.NewRecorder()
+	rec.Header().Set("X-Removed", "true")
+	rec.Header().Set("X-Kept", "true")
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assertInt(t, http.StatusAccepted, rec.Code)
+	assertString(t, "hello world", rec.Body.String())
+	assertString(t, "true", rec.Header().Get("X-Custom"))
+	assertString(t, "true", rec.Header().Get("X-Kept"))
+	assertString(t, "", rec.Header().Get("X-Removed"))
+}
+
+func TestBufferedLimit(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	mux := New(WithBuffering(8), WithLogger(log.New(buf, "", 0)))
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
+		fmt.Fprintf(w, "hello")
+		fmt.Fprintf(w, " world")
+		w.Header().Set("X-Late", "true")
+		fmt.Fprintf(w, "!")
+		return NewErrorf(http.StatusTeapot, "fail")
+	})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assertInt(t, http.StatusOK, rec.Code)
+	assertString(t, "hello world!", rec.Body.String())
+	assertString(t, "", rec.Result().Header.Get("X-Late"))
+	if !strings.Contains(buf.String(), "header already sent") {
+		t.Errorf("Late error not found in log output.\nGot: %s", buf.String())
+	}
+}
+
+func TestBufferedFlush(t *testing.T) {
+	mux := New(WithBuffering(1024))
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
+		fmt.Fprintf(w, "hello")
+		w.(http.Flusher).Flush()
+		return NewErrorf(http.StatusTeapot, "fail")
+	})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assertInt(t, http.StatusOK, rec.Code)
+	if !rec.Flushed {
+		t.Fatal("Flush should flush the underlying writer")
+	}
+	assertString(t, "hello", rec.Body.String())
+}
+
+func TestBufferedPanic(t *testing.T) {
+	mux := New(WithBuffering(1024), WithPanicRecovery(true), WithErrorEncoder(EncodeText))
+	defer setLogOutput(mux, ioutil.Discard)()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
+		fmt.Fprintf(w, "partial")
+		panic(NewErrorf(http.StatusTeapot, "fail"))
+	})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assertInt(t, http.StatusTeapot, rec.Code)
+	if strings.Contains(rec.Body.String(), "partial") {
+		t.Fatalf("Buffered response should be discarded.\nGot: %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,138 @@
+package ehttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CatalogEntry describes a registered application error code.
+type CatalogEntry struct {
+	Code    string `json:"code"`
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// errorCatalog is the registry of the application error codes.
+type errorCatalog struct {
+	mu      sync.RWMutex
+	entries map[string]CatalogEntry
+}
+
+// defaultCatalog is the catalog used by RegisterCode.
+var defaultCatalog = &errorCatalog{}
+
+// register adds the code to the catalog and returns the matching error.
+func (c *errorCatalog) register(code string, status int, defaultMessage string) (*Error, error) {
+	if code == "" {
+		return nil, errors.New("ehttp: empty error code")
+	}
+	if status < http.StatusBadRequest || status > 599 {
+		return nil, fmt.Errorf("ehttp: invalid http status %d for error code %q", status, code)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[code]; ok {
+		return nil, fmt.Errorf("ehttp: error code %q already registered", code)
+	}
+	if c.entries == nil {
+		c.entries = map[string]CatalogEntry{}
+	}
+	c.entries[code] = CatalogEntry{Code: code, Status: status, Message: defaultMessage}
+	return &Error{
+		code:      status,
+		error:     errors.New(defaultMessage),
+		public:    defaultMessage,
+		errorCode: code,
+	}, nil
+}
+
+// list returns the catalog entries sorted by code.
+func (c *errorCatalog) list() []CatalogEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entries := make([]CatalogEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Code < entries[j].Code })
+	return entries
+}
+
+// RegisterCode registers the application error code in the catalog and returns the matching error,
+// sent with the given http status and default message as public message.
+// Fails if the code is already registered or if the status is not a 4xx/5xx one.
+func RegisterCode(code string, status int, defaultMessage string) (*Error, error) {
+	return defaultCatalog.register(code, status, defaultMessage)
+}
+
+// MustRegisterCode is like RegisterCode but panics on failure.
+// Meant to declare the errors as package variables:
+//
+//	var ErrUserNotFound = ehttp.MustRegisterCode("USER_NOT_FOUND", http.StatusNotFound, "user not found")
+func MustRegisterCode(code string, status int, defaultMessage string) *Error {
+	e, err := RegisterCode(code, status, defaultMessage)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Catalog returns the registered application error codes sorted by code.
+func Catalog() []CatalogEntry {
+	return defaultCatalog.list()
+}
+
+// WriteCatalogJSON writes the registered application error codes as a JSON array.
+func WriteCatalogJSON(w io.Writer) error {
+	return writeCatalogJSON(w, Catalog())
+}
+
+// WriteCatalogMarkdown writes the registered application error codes as a Markdown table.
+func WriteCatalogMarkdown(w io.Writer) error {
+	return writeCatalogMarkdown(w, Catalog())
+}
+
+// writeCatalogJSON writes the given entries as an indented JSON array.
+func writeCatalogJSON(w io.Writer, entries []CatalogEntry) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// writeCatalogMarkdown writes the given entries as a Markdown table.
+// The messages are escaped so they fit in a cell.
+func writeCatalogMarkdown(w io.Writer, entries []CatalogEntry) error {
+	var buf strings.Builder
+	buf.WriteString("| Code | Status | Message |\n")
+	buf.WriteString("| ---- | ------ | ------- |\n")
+	for _, e := range entries {
+		fmt.Fprintf(&buf, "| `%s` | %d %s | %s |\n", e.Code, e.Status, http.StatusText(e.Status), markdownCell(e.Message))
+	}
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// markdownCell escapes the pipes and replaces the line breaks of the given text
+// so it fits in a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>").Replace(s)
+}
+
+// CodeOf returns the application error code of the first ehttp error carrying one in the error tree.
+func CodeOf(err error) string {
+	var code string
+	walkErrors(err, func(err error) bool {
+		if e1, ok := err.(*Error); ok && e1.errorCode != "" {
+			code = e1.errorCode
+			return false
+		}
+		return true
+	})
+	return code
+}
@@ -0,0 +1,131 @@
+package ehttp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var errCatalogTestNotFound = MustRegisterCode("CATALOG_TEST_NOT_FOUND", http.StatusNotFound, "item not found")
+
+func TestRegisterCode(t *testing.T) {
+	assertInt(t, http.StatusNotFound, errCatalogTestNotFound.Code())
+	assertString(t, "CATALOG_TEST_NOT_FOUND", errCatalogTestNotFound.ErrorCode())
+	assertString(t, "item not found", PublicMessage(errCatalogTestNotFound))
+
+	if _, err := RegisterCode("CATALOG_TEST_NOT_FOUND", http.StatusGone, "gone"); err == nil {
+		t.Fatal("Duplicate code should fail")
+	}
+	if _, err := RegisterCode("", http.StatusGone, "gone"); err == nil {
+		t.Fatal("Empty code should fail")
+	}
+	if _, err := RegisterCode("CATALOG_TEST_OK", http.StatusOK, "ok"); err == nil {
+		t.Fatal("Non error status should fail")
+	}
+
+	found := false
+	for _, entry := range Catalog() {
+		if entry.Code == "CATALOG_TEST_NOT_FOUND" {
+			found = true
+			assertInt(t, http.StatusNotFound, entry.Status)
+			assertString(t, "item not found", entry.Message)
+		}
+	}
+	if !found {
+		t.Fatal("Registered code not found in the catalog")
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	other := NotFound.WithErrorCode("CATALOG_TEST_OTHER")
+	err := fmt.Errorf("wrapped: %w", errCatalogTestNotFound)
+
+	assertString(t, "CATALOG_TEST_NOT_FOUND", CodeOf(err))
+	assertString(t, "CATALOG_TEST_OTHER", CodeOf(errors.Join(NotFound, other)))
+	assertString(t, "CATALOG_TEST_OTHER", CodeOf(NewError(http.StatusGone, other)))
+	assertString(t, "", CodeOf(NotFound))
+	assertString(t, "", CodeOf(nil))
+
+	// The code must match when set on the target.
+	if !errors.Is(err, errCatalogTestNotFound) || !errors.Is(err, NotFound) {
+		t.Error("Error should match its code and status")
+	}
+	if errors.Is(err, other) {
+		t.Error("Error should not match a different code")
+	}
+}
+
+func TestErrorCodeEncoders(t *testing.T) {
+	for _, tc := range []struct {
+		encoder ErrorEncoder
+		expect  string
+	}{
+		{encoder: EncodeJSON, expect: `{"code":"CATALOG_TEST_NOT_FOUND","errors":["item not found"]}` + "\n"},
+		{encoder: EncodeText, expect: "CATALOG_TEST_NOT_FOUND: item not found\n"},
+		{encoder: EncodeXML, expect: `<errors code="CATALOG_TEST_NOT_FOUND"><error>item not found</error></errors>`},
+		{encoder: EncodeProblem, expect: `{"code":"CATALOG_TEST_NOT_FOUND","detail":"item not found","instance":"/","status":404,"title":"Not Found"}` + "\n"},
+		{encoder: HTMLEncoder(nil), expect: "<p><code>CATALOG_TEST_NOT_FOUND</code></p>"},
+	} {
+		mux := New(WithErrorEncoder(tc.encoder))
+		mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
+			return errCatalogTestNotFound
+		})
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		assertInt(t, http.StatusNotFound, rec.Code)
+		if !strings.Contains(rec.Body.String(), tc.expect) {
+			t.Errorf("Unexpected body.\nExpect:\t%s\nGot:\t%s", tc.expect, rec.Body)
+		}
+	}
+}
+
+func TestCatalogExport(t *testing.T) {
+	c := &errorCatalog{}
+	if _, err := c.register("USER_NOT_FOUND", http.StatusNotFound, "user not found\ncheck the id"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.register("RATE_LIMITED", http.StatusTooManyRequests, "too many requests | retry later"); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := writeCatalogJSON(buf, c.list()); err != nil {
+		t.Fatal(err)
+	}
+	assertString(t, `[
+  {
+    "code": "RATE_LIMITED",
+    "status": 429,
+    "message": "too many requests | retry later"
+  },
+  {
+    "code": "USER_NOT_FOUND",
+    "status": 404,
+    "message": "user not found\ncheck the id"
+  }
+]
+`, buf.String())
+
+	buf.Reset()
+	if err := writeCatalogMarkdown(buf, c.list()); err != nil {
+		t.Fatal(err)
+	}
+	assertString(t, "| Code | Status | Message |\n"+
+		"| ---- | ------ | ------- |\n"+
+		"| `RATE_LIMITED` | 429 Too Many Requests | too many requests \\| retry later |\n"+
+		"| `USER_NOT_FOUND` | 404 Not Found | user not found<br>check the id |\n", buf.String())
+
+	// Make sure the default catalog exports.
+	buf.Reset()
+	if err := WriteCatalogJSON(buf); err != nil || !strings.Contains(buf.String(), "CATALOG_TEST_NOT_FOUND") {
+		t.Errorf("Unexpected JSON catalog: %s, %v", buf, err)
+	}
+	buf.Reset()
+	if err := WriteCatalogMarkdown(buf); err != nil || !strings.Contains(buf.String(), "CATALOG_TEST_NOT_FOUND") {
+		t.Errorf("Unexpected Markdown catalog: %s, %v", buf, err)
+	}
+}
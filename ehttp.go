@@ -5,42 +5,83 @@ package ehttp
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
-	"os"
-	"path"
-	"runtime"
+	"time"
 )
 
 // ServeMux wraps *net/http.ServeMux for ehttp.
 type ServeMux struct {
 	ServeMux         *http.ServeMux
-	errorContentType string                                     // Content-Type to use for the error cases.
-	recoverPanic     bool                                       // Flag to know whether or not to recover from panics.
-	log              *log.Logger                                // Custom logger to use for errors.
-	sendError        func(ResponseWriter, *http.Request, error) // Callback to send error to the client.
+	errorContentType string              // Content-Type to use for the error cases.
+	recoverPanic     bool                // Flag to know whether or not to recover from panics.
+	log              *slog.Logger        // Custom logger to use for errors. slog.Default() if nil.
+	sendError        ErrorEncoder        // Callback to send error to the client.
+	hooks            []Hooks             // Callbacks invoked when handling errors.
+	debug            bool                // Flag to know whether or not to send the panic stack traces to the client.
+	bufferLimit      int                 // Maximum response size to buffer. Disabled if 0.
+	encoders         []errorEncoderEntry // Encoders registered for content negotiation.
+	middlewares      []Middleware        // Middlewares applied to the handlers.
+	statusPolicy     StatusPolicy        // Policy resolving the status code of the multi errors.
+	requestID        bool                // Flag to know whether or not to handle the request IDs.
+	accessLog        func(*accessEntry)  // Access logger. Disabled if nil.
+}
+
+// ErrorEncoder is the callback sending the error to the client.
+// Called after the http status code has been sent.
+type ErrorEncoder func(ResponseWriter, *http.Request, error)
+
+// New instantiates a new *github.com/creack/ehttp.ServeMux configured with the given options.
+// Without options, behaves like the DefaultServeMux with a new *net/http.ServeMux.
+func New(opts ...Option) *ServeMux {
+	sm := &ServeMux{
+		ServeMux:         http.NewServeMux(),
+		sendError:        EncodeJSON,
+		errorContentType: "application/json; charset=utf-8",
+		recoverPanic:     false,
+	}
+	for _, opt := range opts {
+		opt(sm)
+	}
+	return sm
+}
+
+// With returns a copy of the ServeMux with the given options applied on top of its configuration.
+// The copy shares the underlying *net/http.ServeMux: the handlers registered on the copy
+// are served by both, but use the error management of the copy.
+func (sm *ServeMux) With(opts ...Option) *ServeMux {
+	sm2 := *sm
+	sm2.hooks = append([]Hooks(nil), sm.hooks...)
+	sm2.encoders = append([]errorEncoderEntry(nil), sm.encoders...)
+	sm2.middlewares = append([]Middleware(nil), sm.middlewares...)
+	for _, opt := range opts {
+		opt(&sm2)
+	}
+	return &sm2
 }
 
 // NewServeMux emulates net/http.NewServeMux but returns a *github.com/creack/ehttp.ServeMux.
-// Logger default to the default log.Logger if nil.
-// sendErrorCallback default to `fmt.Fprintf(w, "%s\n", err)` if nil.
+// Logger default to slog.Default() if nil.
+// sendErrorCallback default to EncodeText if nil.
 func NewServeMux(sendErrorCallback func(ResponseWriter, *http.Request, error), errorContentType string, recoverPanic bool, logger *log.Logger) *ServeMux {
-	if logger == nil {
-		logger = log.New(os.Stderr, "", log.LstdFlags)
-	}
 	if sendErrorCallback == nil {
-		sendErrorCallback = func(w ResponseWriter, req *http.Request, err error) {
-			fmt.Fprintf(w, "%s\n", err)
-		}
-	}
-	return &ServeMux{
-		ServeMux:         http.NewServeMux(),
-		sendError:        sendErrorCallback,
-		errorContentType: errorContentType,
-		recoverPanic:     recoverPanic,
-		log:              logger,
+		sendErrorCallback = EncodeText
 	}
+	return New(
+		WithErrorEncoder(sendErrorCallback),
+		WithContentType(errorContentType),
+		WithPanicRecovery(recoverPanic),
+		WithLogger(logger),
+	)
+}
+
+// RecoverPanic returns whether or not the ServeMux recovers from panics.
+// Exposed to be accessed from adaptor subpackages.
+func (sm *ServeMux) RecoverPanic() bool {
+	return sm.recoverPanic
 }
 
 // HandlerFunc converts the github.com/creack/ehttp.HandlerFunc handler to a standard http.Handler.
@@ -58,109 +99,160 @@ func (sm *ServeMux) HandleFunc(pattern string, handler func(http.ResponseWriter,
 }
 
 // ServeHTTP implements http.Handler interface.
+// When no pattern matches the request, the 404 / 405 errors of the underlying
+// *net/http.ServeMux are sent via HandleError.
+// Every request is logged once served, if the access logs are enabled.
 func (sm *ServeMux) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	sm.ServeMux.ServeHTTP(w, req)
+	sm.ServeAccessLog(w, sm.withRequestID(w, req), http.HandlerFunc(sm.serveHTTP))
+}
+
+// serveHTTP dispatches the request to the underlying *net/http.ServeMux.
+func (sm *ServeMux) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	if _, pattern := sm.ServeMux.Handler(req); pattern != "" {
+		sm.ServeMux.ServeHTTP(w, req)
+		return
+	}
+	iw := &statusInterceptor{ResponseWriter: w}
+	sm.ServeMux.ServeHTTP(iw, req)
+	if iw.code != 0 {
+		sm.ServeError(w, req, newStatusError(iw.code))
+	}
+}
+
+// ServeError sends the given error like a handler returning it would, without calling any handler.
+// Should not be manually called. Exposed to be accessed from adaptor subpackages.
+func (sm *ServeMux) ServeError(w http.ResponseWriter, req *http.Request, err error) {
+	start := time.Now()
+	ww := NewResponseWriter(w)
+	req = sm.withRequestID(ww, req)
+	sm.HandleError(ww, req, err)
+	sm.recordAccess(ww, req, start, err)
 }
 
 // MWError is the main middleware. When an error is returned, it send
 // the data to the client if the header hasn't been sent yet, otherwise, log them.
+// The middlewares registered with Use are applied to the handler.
+// If the buffer limit is set, the response is buffered, see Buffered.
 func (sm *ServeMux) MWError(handler HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, req *http.Request) {
-		ww := NewResponseWriter(w)
-		if err := handler(ww, req); err != nil {
-			sm.HandleError(ww, req, err)
-			return
-		}
-	}
+	return sm.mwError(sm.chain(handler))
 }
 
 // MWErrorPanic wraps MWError and recovers from panic.
+// The middlewares are applied within the recovery.
 func (sm *ServeMux) MWErrorPanic(handler HandlerFunc) http.HandlerFunc {
-	return sm.MWError(func(w http.ResponseWriter, req *http.Request) (err error) {
+	handler = sm.chain(handler)
+	return sm.mwError(func(w http.ResponseWriter, req *http.Request) (err error) {
 		defer func() {
 			if e1 := recover(); e1 != nil {
-				var name string
-				skip := 0
-			begin:
-				pc, file, line, ok := runtime.Caller(3 + skip)
-				if ok {
-					name = runtime.FuncForPC(pc).Name()
-					name = path.Base(name)
-					file = path.Base(file)
-					// If there is a runtime panic (nil dereference or other) we endup with a runtime callstack. Skip until out of it.
-					if len(name) > 7 && name[:7] == "runtime" {
-						skip++
-						goto begin
-					}
-				}
 				err = sm.HandlePanic(err, e1)
-				if e2, ok := err.(*Error); ok {
-					e2.error = fmt.Errorf("[%s %s:%d] %s", name, file, line, e2.error)
-				} else {
-					err = fmt.Errorf("[%s %s:%d] %s", name, file, line, err)
-				}
 			}
 		}()
 		return handler(w, req)
 	})
 }
 
+// mwError handles the error returned by the given handler.
+func (sm *ServeMux) mwError(handler HandlerFunc) http.HandlerFunc {
+	if sm.bufferLimit > 0 {
+		handler = Buffered(sm.bufferLimit, handler)
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		ww := NewResponseWriter(w)
+		req = sm.withRequestID(ww, req)
+		err := handler(ww, req)
+		if err != nil {
+			sm.HandleError(ww, req, err)
+		}
+		sm.recordAccess(ww, req, start, err)
+	}
+}
+
 // HandleError handles the returned error from the MWError middleware.
 // Should not be manually called. Exposed to be accessed from adaptor subpackages.
 // If the error is nil, then no http code is yielded.
+// The headers of the error (see HeaderError) are set before sending the status code.
+// The server errors (5xx) are logged with their cause, which is not sent to the client.
 func (sm *ServeMux) HandleError(w ResponseWriter, req *http.Request, err error) {
+	req = sm.withRequestID(w, req)
+	var pe *PanicError
+	if errors.As(err, &pe) {
+		sm.logError(req, "Handler panic recovered", err, slog.Any("panic", pe.Value), slog.String("stack", pe.Stack()))
+	}
 	if code := w.Code(); code != 0 {
-		sm.log.Printf("HTTP Error (header already sent): %s (%d)", err, code)
+		sm.logError(req, "HTTP Error (header already sent)", err, slog.Int("status", code), slog.Int64("bytes", w.BytesWritten()))
+		sm.runHooks(w, req, err, code, true)
 		return
 	}
-	if sm.errorContentType != "" {
-		w.Header().Set("Content-Type", sm.errorContentType)
+	sendError, contentType := sm.negotiate(req)
+	if len(sm.encoders) > 0 {
+		w.Header().Add("Vary", "Accept")
+	}
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
 	}
 	if err == nil {
 		return
 	}
-	if e1, ok := err.(*Error); ok && e1.Code() != 0 {
-		w.WriteHeader(e1.Code())
+	setErrorHeaders(w.Header(), err)
+	code := sm.statusCode(err)
+	if code >= http.StatusInternalServerError && pe == nil {
+		// The cause of server errors is not sent to the client, keep it in the logs. Panics are already logged.
+		sm.logError(req, "HTTP Error", err, slog.Int("status", code))
+	}
+	w.WriteHeader(code)
+
+	if sm.debug {
+		sendError(w, req, debugError{err})
 	} else {
-		w.WriteHeader(http.StatusInternalServerError)
+		sendError(w, req, err)
 	}
+	sm.runHooks(w, req, err, code, false)
+}
 
-	sm.sendError(w, req, err)
+// statusCode looks up the http status code to send for the given error.
+// The first *Error found in the error tree (including wrapped errors and errors.Join trees)
+// with a non-zero code is used, then the first StatusCoder. Defaults to http.StatusInternalServerError.
+func statusCode(err error) int {
+	var e1 *Error
+	if errors.As(err, &e1) && e1.Code() != 0 {
+		return e1.Code()
+	}
+	var sc StatusCoder
+	if errors.As(err, &sc) && sc.StatusCode() != 0 {
+		return sc.StatusCode()
+	}
+	return http.StatusInternalServerError
 }
 
 // HandlePanic handles the panic from the handler.
 // Should not be manually called. Exposed to be accessed from adaptor subpackages.
+// If the handler already set its error before panicking, the given error is returned
+// with the panic attached, reachable with errors.As, so HandleError logs the panic with the request attributes
+// and calls the hooks.
 func (sm *ServeMux) HandlePanic(err error, e1 interface{}) error {
 	if e1 == nil { // no panic, return the given error.
 		return err
 	}
-	if err != nil { // an error is already present. log the panic and return the given error.
-		sm.log.Printf("Handler panic recovered: %v", e1)
-		return err
+	pe := newPanicError(e1)
+	if err != nil { // an error is already present. return the given error with the panic.
+		return &handlerPanicError{error: err, panic: pe}
 	}
-	// we have a panic and no given error.
-	e2, ok := e1.(error)
-	if !ok { // the panic is not an error, create an error out of the string representation of the panic.
-		return fmt.Errorf("(%T) %v", e1, e1)
-	}
-	// return the panic error
-	return e2
+	// we have a panic and no given error, return the panic error.
+	return pe
 }
 
 // DefaultServeMux is the default ServeMux used by Serve.
 // The behavior of the DefaultServeMux is as follows:
-// - Logger:             Standard log.Logger.
+// - Logger:             slog.Default().
 // - Content-Type:       "application/json; charset=utf-8"
-// - SendError callback: Send errors wraps in a json object with the key "errors" as a type []string.
+// - SendError callback: Send the public error messages wrapped in a json object with the key "errors" as a type []string.
 // - RecoverPanic:       false.
 var DefaultServeMux = &ServeMux{
-	ServeMux: http.DefaultServeMux,
-	sendError: func(w ResponseWriter, _ *http.Request, err error) {
-		_ = json.NewEncoder(w).Encode(&JSONError{Errors: []string{err.Error()}})
-	},
+	ServeMux:         http.DefaultServeMux,
+	sendError:        EncodeJSON,
 	errorContentType: "application/json; charset=utf-8",
 	recoverPanic:     false,
-	log:              log.New(os.Stderr, "", log.LstdFlags),
 }
 
 // HandlerFunc is the custom http handler function extending the standard one with
@@ -179,7 +271,34 @@ func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 
 // JSONError is the default struct returned to the client upon error.
 type JSONError struct {
-	Errors []string `json:"errors"`
+	Code      string       `json:"code,omitempty"` // Application error code, see RegisterCode.
+	Errors    []string     `json:"errors"`
+	Fields    []FieldError `json:"fields,omitempty"`     // Validation failures, see ValidationError.
+	RequestID string       `json:"request_id,omitempty"` // Request ID, see WithRequestID.
+}
+
+// EncodeJSON is the default ErrorEncoder. Sends the public messages of the error wrapped in a JSONError.
+func EncodeJSON(w ResponseWriter, req *http.Request, err error) {
+	_ = json.NewEncoder(w).Encode(&JSONError{
+		Code:      CodeOf(err),
+		Errors:    PublicMessages(err),
+		Fields:    fieldErrors(err),
+		RequestID: requestIDFromRequest(req),
+	})
+}
+
+// EncodeText is an ErrorEncoder sending the public message of the error as plain text.
+// The message is prefixed with the application error code, if any,
+// and followed by the request ID line, if any.
+func EncodeText(w ResponseWriter, req *http.Request, err error) {
+	if code := CodeOf(err); code != "" {
+		fmt.Fprintf(w, "%s: %s\n", code, PublicMessage(err))
+	} else {
+		fmt.Fprintf(w, "%s\n", PublicMessage(err))
+	}
+	if id := requestIDFromRequest(req); id != "" {
+		fmt.Fprintf(w, "request id: %s\n", id)
+	}
 }
 
 // HandleError exposes HandleError from the DefaultServeMux.
@@ -2,18 +2,18 @@ package ehttp
 
 import (
 	"bytes"
+	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"strings"
 	"testing"
 )
 
 func TestHandleErrorNil(t *testing.T) {
 	buf := bytes.NewBuffer(nil)
-	DefaultServeMux.log.SetOutput(buf)
-	defer DefaultServeMux.log.SetOutput(os.Stderr)
+	defer setLogOutput(DefaultServeMux, buf)()
 
 	rec := httptest.NewRecorder()
 	w := NewResponseWriter(rec)
@@ -25,21 +25,21 @@ func TestHandleErrorNil(t *testing.T) {
 
 func TestHandleErrorCommon(t *testing.T) {
 	buf := bytes.NewBuffer(nil)
-	DefaultServeMux.log.SetOutput(buf)
-	defer DefaultServeMux.log.SetOutput(os.Stderr)
+	defer setLogOutput(DefaultServeMux, buf)()
 
 	rec := httptest.NewRecorder()
 	w := NewResponseWriter(rec)
 	HandleError(w, nil, fmt.Errorf("fail"))
 	assertInt(t, http.StatusInternalServerError, w.Code())
-	assertInt(t, 0, buf.Len())
-	assertJSONError(t, "fail", rec.Body.String())
+	if !strings.Contains(buf.String(), "error=fail") || !strings.Contains(buf.String(), fmt.Sprintf("status=%d", http.StatusInternalServerError)) {
+		t.Errorf("Error and status code not found in log output.\nGot: %s", buf.String())
+	}
+	assertJSONError(t, "Internal Server Error", rec.Body.String())
 }
 
 func TestHandleErrorEHTTP(t *testing.T) {
 	buf := bytes.NewBuffer(nil)
-	DefaultServeMux.log.SetOutput(buf)
-	defer DefaultServeMux.log.SetOutput(os.Stderr)
+	defer setLogOutput(DefaultServeMux, buf)()
 
 	rec := httptest.NewRecorder()
 	w := NewResponseWriter(rec)
@@ -51,8 +51,7 @@ func TestHandleErrorEHTTP(t *testing.T) {
 
 func TestHandleErrorSentHeader(t *testing.T) {
 	buf := bytes.NewBuffer(nil)
-	DefaultServeMux.log.SetOutput(buf)
-	defer DefaultServeMux.log.SetOutput(os.Stderr)
+	defer setLogOutput(DefaultServeMux, buf)()
 
 	rec := httptest.NewRecorder()
 	w := NewResponseWriter(rec)
@@ -62,8 +61,31 @@ func TestHandleErrorSentHeader(t *testing.T) {
 	}
 	HandleError(w, nil, NewErrorf(http.StatusTeapot, "fail"))
 	assertInt(t, http.StatusBadGateway, w.Code())
-	if !strings.Contains(buf.String(), fmt.Sprintf("%s (%d)", "fail", http.StatusBadGateway)) {
+	if !strings.Contains(buf.String(), "error=fail") || !strings.Contains(buf.String(), fmt.Sprintf("status=%d", http.StatusBadGateway)) {
 		t.Errorf("Error and status code not found in log output.\nGot: %s", buf.String())
 	}
 	assertString(t, "hello", rec.Body.String())
 }
+
+func TestHandleErrorWrapped(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	defer setLogOutput(DefaultServeMux, buf)()
+
+	rec := httptest.NewRecorder()
+	w := NewResponseWriter(rec)
+	HandleError(w, nil, fmt.Errorf("loading user: %w", NotFound))
+	assertInt(t, http.StatusNotFound, w.Code())
+	assertInt(t, 0, buf.Len())
+	assertJSONError(t, "Not Found", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	w = NewResponseWriter(rec)
+	HandleError(w, nil, fmt.Errorf("select * from users where id=5: %w", NewError(http.StatusNotFound, sql.ErrNoRows)))
+	assertInt(t, http.StatusNotFound, w.Code())
+	assertJSONError(t, "Not Found", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	w = NewResponseWriter(rec)
+	HandleError(w, nil, errors.Join(fmt.Errorf("fail"), NewErrorf(http.StatusTeapot, "teapot")))
+	assertInt(t, http.StatusTeapot, w.Code())
+}
@@ -2,20 +2,19 @@ package ehttp
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"strings"
 	"testing"
 )
 
 func TestHandlePanicNil(t *testing.T) {
 	buf := bytes.NewBuffer(nil)
-	DefaultServeMux.log.SetOutput(buf)
-	defer DefaultServeMux.log.SetOutput(os.Stderr)
+	defer setLogOutput(DefaultServeMux, buf)()
 
 	if err := HandlePanic(nil, nil); err != nil {
 		t.Fatal(err)
@@ -25,8 +24,7 @@ func TestHandlePanicNil(t *testing.T) {
 
 func TestHandlePanicError(t *testing.T) {
 	buf := bytes.NewBuffer(nil)
-	DefaultServeMux.log.SetOutput(buf)
-	defer DefaultServeMux.log.SetOutput(os.Stderr)
+	defer setLogOutput(DefaultServeMux, buf)()
 
 	err := HandlePanic(nil, fmt.Errorf("fail"))
 	assertInt(t, 0, buf.Len())
@@ -35,8 +33,7 @@ func TestHandlePanicError(t *testing.T) {
 
 func TestHandlePanicIncomingError(t *testing.T) {
 	buf := bytes.NewBuffer(nil)
-	DefaultServeMux.log.SetOutput(buf)
-	defer DefaultServeMux.log.SetOutput(os.Stderr)
+	defer setLogOutput(DefaultServeMux, buf)()
 
 	e1 := fmt.Errorf("fail")
 	err := HandlePanic(e1, nil)
@@ -49,24 +46,26 @@ func TestHandlePanicIncomingError(t *testing.T) {
 
 func TestHandlePanicBothError(t *testing.T) {
 	buf := bytes.NewBuffer(nil)
-	DefaultServeMux.log.SetOutput(buf)
-	defer DefaultServeMux.log.SetOutput(os.Stderr)
+	defer setLogOutput(DefaultServeMux, buf)()
 
 	e1 := fmt.Errorf("hello")
 	err := HandlePanic(e1, fmt.Errorf("fail"))
-	if err != e1 {
+	if !errors.Is(err, e1) {
 		t.Errorf("error passed to HandlePanic does not match returned one when error already present")
 	}
-	if !strings.Contains(buf.String(), "panic recovered: fail") {
-		t.Errorf("Panic error value not found in log output.\nGot: %s", buf.String())
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("The panic should be attached to the returned error")
 	}
+	assertString(t, "fail", pe.Value.(error).Error())
+	// The panic is logged when handling the error.
+	assertInt(t, 0, buf.Len())
 	assertString(t, "hello", err.Error())
 }
 
 func TestHandlePanicNonError(t *testing.T) {
 	buf := bytes.NewBuffer(nil)
-	DefaultServeMux.log.SetOutput(buf)
-	defer DefaultServeMux.log.SetOutput(os.Stderr)
+	defer setLogOutput(DefaultServeMux, buf)()
 
 	err := HandlePanic(nil, "fail")
 	assertInt(t, 0, buf.Len())
@@ -96,8 +95,37 @@ func TestFullHandlePanicError(t *testing.T) {
 		t.Fatalf("Error reading body from test server request: %s", err)
 	}
 
-	assertInt(t, 0, buf.Len())
+	// The panic is logged with its stack.
+	if !strings.Contains(buf.String(), `msg="Handler panic recovered"`) || !strings.Contains(buf.String(), "stack=") {
+		t.Errorf("Panic and stack not found in log output.\nGot: %s", buf.String())
+	}
+	if strings.Contains(string(body), "github.com/creack/ehttp.TestFullHandlePanicError") {
+		t.Errorf("Stack trace should not be sent to the client.\nGot: %s", body)
+	}
 	if !strings.Contains(string(body), "fail") {
 		t.Fatalf("Unexpected response body from panic. Expected to see %q, got: %q", "fail", body)
 	}
 }
+
+func TestHandlePanicBothErrorLog(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	mux := NewServeMux(nil, "", false, log.New(buf, "", log.LstdFlags))
+
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, req *http.Request) (err error) {
+		defer func() { err = mux.HandlePanic(err, recover()) }()
+		err = NewErrorf(http.StatusTeapot, "hello")
+		panic("fail")
+	})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/panic", nil))
+	assertInt(t, http.StatusTeapot, rec.Code)
+
+	// The panic is logged once, with the request attributes.
+	assertInt(t, 1, strings.Count(buf.String(), `msg="Handler panic recovered"`))
+	for _, expect := range []string{"method=GET", "path=/panic", "pattern=/panic", "panic=fail", "error=hello"} {
+		if !strings.Contains(buf.String(), expect) {
+			t.Errorf("%q not found in log output.\nGot: %s", expect, buf.String())
+		}
+	}
+}
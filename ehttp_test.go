@@ -1,11 +1,13 @@
 package ehttp
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -29,7 +31,7 @@ func TestHandleFunc(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	assertJSONError(t, "fail", string(body))
+	assertJSONError(t, "Internal Server Error", string(body))
 }
 
 func TestServeHTTP(t *testing.T) {
@@ -52,7 +54,7 @@ func TestServeHTTP(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	assertJSONError(t, "fail", string(body))
+	assertJSONError(t, "Internal Server Error", string(body))
 }
 
 func TestMWErrorPanicCommon(t *testing.T) {
@@ -62,6 +64,8 @@ func TestMWErrorPanicCommon(t *testing.T) {
 		name string
 		line int
 	)
+	buf := bytes.NewBuffer(nil)
+	defer setLogOutput(DefaultServeMux, buf)()
 	hdlr := func(w http.ResponseWriter, req *http.Request) error {
 		name, file, line = getCallstack(0)
 		panic(fmt.Errorf("fail"))
@@ -81,7 +85,11 @@ func TestMWErrorPanicCommon(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	assertJSONError(t, fmt.Sprintf("[%s %s:%d] fail", name, file, line+1), string(body))
+	// The internal error is not sent to the client, but logged.
+	assertJSONError(t, "Internal Server Error", string(body))
+	if expect := fmt.Sprintf("error=\"[%s %s:%d] fail\"", name, file, line+1); !strings.Contains(buf.String(), expect) {
+		t.Errorf("Panic error not found in log output.\nExpect:\t%s\nGot:\t%s", expect, buf)
+	}
 }
 
 func TestMWErrorPanicEHTTP(t *testing.T) {
@@ -91,6 +99,8 @@ func TestMWErrorPanicEHTTP(t *testing.T) {
 		name string
 		line int
 	)
+	buf := bytes.NewBuffer(nil)
+	defer setLogOutput(DefaultServeMux, buf)()
 	hdlr := func(w http.ResponseWriter, req *http.Request) error {
 		name, file, line = getCallstack(0)
 		panic(NewErrorf(http.StatusTeapot, "fail"))
@@ -110,7 +120,11 @@ func TestMWErrorPanicEHTTP(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	assertJSONError(t, fmt.Sprintf("[%s %s:%d] fail", name, file, line+1), string(body))
+	// The panic location is part of the stack, only logged.
+	assertJSONError(t, "fail", string(body))
+	if expect := fmt.Sprintf("[%s %s:%d] fail", name, file, line+1); !strings.Contains(buf.String(), expect) {
+		t.Errorf("Panic location not found in log output.\nExpect:\t%s\nGot:\t%s", expect, buf.String())
+	}
 }
 
 func TestMWErrorPanicInt(t *testing.T) {
@@ -120,6 +134,8 @@ func TestMWErrorPanicInt(t *testing.T) {
 		name string
 		line int
 	)
+	buf := bytes.NewBuffer(nil)
+	defer setLogOutput(DefaultServeMux, buf)()
 	hdlr := func(w http.ResponseWriter, req *http.Request) error {
 		name, file, line = getCallstack(0)
 		panic(http.StatusTeapot)
@@ -139,7 +155,11 @@ func TestMWErrorPanicInt(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	assertJSONError(t, fmt.Sprintf("[%s %s:%d] (int) %d", name, file, line+1, http.StatusTeapot), string(body))
+	// The internal error is not sent to the client, but logged.
+	assertJSONError(t, "Internal Server Error", string(body))
+	if expect := fmt.Sprintf("error=\"[%s %s:%d] (int) %d\"", name, file, line+1, http.StatusTeapot); !strings.Contains(buf.String(), expect) {
+		t.Errorf("Panic error not found in log output.\nExpect:\t%s\nGot:\t%s", expect, buf)
+	}
 }
 
 func TestMWErrorPanicMiddleware(t *testing.T) {
@@ -149,6 +169,8 @@ func TestMWErrorPanicMiddleware(t *testing.T) {
 		name string
 		line int
 	)
+	buf := bytes.NewBuffer(nil)
+	defer setLogOutput(DefaultServeMux, buf)()
 	hdlr := func(w http.ResponseWriter, req *http.Request) error {
 		name, file, line = getCallstack(0)
 		panic(fmt.Errorf("fail"))
@@ -173,7 +195,11 @@ func TestMWErrorPanicMiddleware(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	assertJSONError(t, fmt.Sprintf("[%s %s:%d] fail", name, file, line+1), string(body))
+	// The internal error is not sent to the client, but logged.
+	assertJSONError(t, "Internal Server Error", string(body))
+	if expect := fmt.Sprintf("error=\"[%s %s:%d] fail\"", name, file, line+1); !strings.Contains(buf.String(), expect) {
+		t.Errorf("Panic error not found in log output.\nExpect:\t%s\nGot:\t%s", expect, buf)
+	}
 }
 
 func TestMWErrorPanicRuntimePanic(t *testing.T) {
@@ -183,6 +209,8 @@ func TestMWErrorPanicRuntimePanic(t *testing.T) {
 		name string
 		line int
 	)
+	buf := bytes.NewBuffer(nil)
+	defer setLogOutput(DefaultServeMux, buf)()
 	hdlr := func(w http.ResponseWriter, req *http.Request) error {
 		name, file, line = getCallstack(0)
 		_ = (*http.Request)(nil).Body // Expected nil pointer dereference for test.
@@ -208,7 +236,11 @@ func TestMWErrorPanicRuntimePanic(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	assertJSONError(t, fmt.Sprintf("[%s %s:%d] runtime error: invalid memory address or nil pointer dereference", name, file, line+1), string(body))
+	// The internal error is not sent to the client, but logged.
+	assertJSONError(t, "Internal Server Error", string(body))
+	if expect := fmt.Sprintf("error=\"[%s %s:%d] runtime error: invalid memory address or nil pointer dereference\"", name, file, line+1); !strings.Contains(buf.String(), expect) {
+		t.Errorf("Panic error not found in log output.\nExpect:\t%s\nGot:\t%s", expect, buf)
+	}
 }
 
 func TestDefaultMWError(t *testing.T) {
@@ -242,7 +274,7 @@ func TestDefaultMWError(t *testing.T) {
 		t.Fatalf("Error parsing error output: %s", err)
 	}
 	assertInt(t, 1, len(jsonErr.Errors))
-	assertString(t, "fail a", jsonErr.Errors[0])
+	assertString(t, "Internal Server Error", jsonErr.Errors[0])
 
 	// Test /b.
 	resp, err = http.Get(ts.URL + "/b")
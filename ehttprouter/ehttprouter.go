@@ -5,6 +5,9 @@ package ehttprouter
 import (
 	"log"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/creack/ehttp"
 	"github.com/julienschmidt/httprouter"
@@ -18,58 +21,200 @@ type Router struct {
 	*httprouter.Router                 // Underlying httprouter.Router.
 	mux                *ehttp.ServeMux // ehttp mux.
 	recoverPanic       bool            // Flag to know whether or not handle panics.
+	middlewares        []Middleware    // Middlewares applied to the handles.
+	prefix             string          // Path prefix of the group. Empty for the root router.
+	errorMuxes         *errorMuxes     // Muxes sending the errors of the httprouter hooks. Shared by the groups.
 }
 
-// DefaultRouter is the default router for direct access.
-var DefaultRouter = &Router{
-	Router:       httprouter.New(),
-	mux:          ehttp.DefaultServeMux,
-	recoverPanic: false,
+// errorMuxes selects the mux sending the errors of the httprouter NotFound, MethodNotAllowed
+// and PanicHandler hooks: the mux of the group owning the request path, the root mux otherwise.
+type errorMuxes struct {
+	mu     sync.RWMutex
+	root   *ehttp.ServeMux
+	groups []groupMux // Sorted by decreasing prefix length.
+}
+
+// groupMux is the mux of a group configured with With.
+type groupMux struct {
+	prefix string
+	mux    *ehttp.ServeMux
+}
+
+// register sets the mux of the paths under the given prefix.
+func (m *errorMuxes) register(prefix string, mux *ehttp.ServeMux) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, g := range m.groups {
+		if g.prefix == prefix {
+			m.groups[i].mux = mux
+			return
+		}
+	}
+	m.groups = append(m.groups, groupMux{prefix: prefix, mux: mux})
+	sort.SliceStable(m.groups, func(i, j int) bool { return len(m.groups[i].prefix) > len(m.groups[j].prefix) })
 }
 
+// lookup returns the mux of the given path.
+func (m *errorMuxes) lookup(path string) *ehttp.ServeMux {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, g := range m.groups {
+		if path == g.prefix || strings.HasPrefix(path, g.prefix+"/") {
+			return g.mux
+		}
+	}
+	return m.root
+}
+
+// DefaultRouter is the default router for direct access.
+var DefaultRouter = newRouter(ehttp.DefaultServeMux)
+
 // New instantiates a new ehttprouter.Router.
 func New(sendErrorCallback func(ehttp.ResponseWriter, *http.Request, error), errorContentType string, recoverPanic bool, logger *log.Logger) *Router {
-	return &Router{
+	return newRouter(ehttp.NewServeMux(sendErrorCallback, errorContentType, recoverPanic, logger))
+}
+
+// NewWithOptions instantiates a new ehttprouter.Router configured with the given ehttp options.
+func NewWithOptions(opts ...ehttp.Option) *Router {
+	return newRouter(ehttp.New(opts...))
+}
+
+// newRouter instantiates a new ehttprouter.Router using the given mux for the error management.
+// The httprouter NotFound, MethodNotAllowed and PanicHandler (if the recoverPanic flag is set)
+// hooks send their errors via the mux, or via the mux of the group owning the path, see With.
+func newRouter(mux *ehttp.ServeMux) *Router {
+	r := &Router{
 		Router:       httprouter.New(),
-		mux:          ehttp.NewServeMux(sendErrorCallback, errorContentType, recoverPanic, logger),
-		recoverPanic: recoverPanic,
+		mux:          mux,
+		recoverPanic: mux.RecoverPanic(),
+		errorMuxes:   &errorMuxes{root: mux},
+	}
+	r.Router.NotFound = http.HandlerFunc(r.notFound)
+	r.Router.MethodNotAllowed = http.HandlerFunc(r.methodNotAllowed)
+	if r.recoverPanic {
+		r.Router.PanicHandler = r.panicHandler
+	}
+	return r
+}
+
+// notFound sends ehttp.NotFound when no route matches.
+func (r *Router) notFound(w http.ResponseWriter, req *http.Request) {
+	r.errorMuxes.lookup(req.URL.Path).ServeError(w, req, ehttp.NotFound)
+}
+
+// methodNotAllowed sends ehttp.MethodNotAllowed when the route exists for other methods.
+// The Allow header is set by httprouter beforehand.
+func (r *Router) methodNotAllowed(w http.ResponseWriter, req *http.Request) {
+	r.errorMuxes.lookup(req.URL.Path).ServeError(w, req, ehttp.MethodNotAllowed)
+}
+
+// panicHandler sends the panics which were not recovered by the handles,
+// i.e. from the standard http handlers.
+// If the mux of the path does not recover from panics, the panic is propagated.
+func (r *Router) panicHandler(w http.ResponseWriter, req *http.Request, e1 interface{}) {
+	mux := r.errorMuxes.lookup(req.URL.Path)
+	if !mux.RecoverPanic() {
+		panic(e1)
 	}
+	mux.ServeError(w, req, mux.HandlePanic(nil, e1))
 }
 
-// PanicHandler is a place holder to disable unwanted access to the underlying field.
-// Panic is handled via ehttprouter instead.
-func (r *Router) PanicHandler() {}
+// AddHooks registers the given error hooks on the router's mux.
+func (r *Router) AddHooks(hooks ehttp.Hooks) {
+	r.mux.AddHooks(hooks)
+}
+
+// Group returns a sub-router sharing the underlying router tree.
+// The handles registered on the sub-router have their path prefixed and the given middlewares
+// applied after the ones of the parent router. Groups can be nested.
+// The prefix must begin with '/'.
+func (r *Router) Group(prefix string, mws ...Middleware) *Router {
+	if prefix == "" || prefix[0] != '/' {
+		panic("ehttprouter: group prefix must begin with '/' in '" + prefix + "'")
+	}
+	g := *r
+	g.prefix = r.prefix + strings.TrimSuffix(prefix, "/")
+	g.middlewares = append(append([]Middleware(nil), r.middlewares...), mws...)
+	g.registerErrorMux()
+	return &g
+}
+
+// registerErrorMux sets the mux of the router as the one of the paths under its prefix,
+// if it differs from the mux selected for the prefix.
+func (r *Router) registerErrorMux() {
+	if r.prefix != "" && r.errorMuxes.lookup(r.prefix) != r.mux {
+		r.errorMuxes.register(r.prefix, r.mux)
+	}
+}
+
+// With returns a copy of the router sharing the underlying router tree,
+// with the given ehttp options applied on top of its error management configuration.
+// Combined with Group, allows a different error format or panic policy per group:
+//
+//	admin := router.Group("/admin").With(ehttp.WithProblemDetails(), ehttp.WithPanicRecovery(true))
+//
+// On a group, the configuration also applies to the NotFound and MethodNotAllowed errors
+// and to the panics of the standard handlers under the group prefix.
+func (r *Router) With(opts ...ehttp.Option) *Router {
+	r2 := *r
+	r2.mux = r.mux.With(opts...)
+	r2.recoverPanic = r2.mux.RecoverPanic()
+	r2.middlewares = append([]Middleware(nil), r.middlewares...)
+	r2.registerErrorMux()
+	if r2.recoverPanic && r2.Router.PanicHandler == nil {
+		r2.Router.PanicHandler = r2.panicHandler
+	}
+	return &r2
+}
 
 // middlewareSelect applies the error middleware.
 // If the recoverPanic flag is set, recover panics, otherwise, just handle errors.
-func (r *Router) middlewareSelect(handle Handle) httprouter.Handle {
+// The route path is exposed as the request Pattern.
+func (r *Router) middlewareSelect(path string, handle Handle) httprouter.Handle {
+	hdlr := func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		req.Pattern = path
+		return handle(w, req, p)
+	}
 	if r.recoverPanic {
-		return r.MWErrorPanic(handle)
+		return r.MWErrorPanic(hdlr)
 	}
-	return r.MWError(handle)
+	return r.MWError(hdlr)
 }
 
 // MWError is the middleware taking care of the returned error.
+// The middlewares registered with Use are applied to the handle.
+// The handler is built once, the params are passed through the request context.
 func (r *Router) MWError(handle func(http.ResponseWriter, *http.Request, httprouter.Params) error) httprouter.Handle {
-	return func(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
-		ww := ehttp.NewResponseWriter(w)
-		if err := handle(ww, req, p); err != nil {
-			r.mux.HandleError(ww, req, err)
-			return
-		}
-	}
+	return withContextParams(r.mux.MWError(contextParams(r.chain(handle))))
 }
 
 // MWErrorPanic wraps MWError and recovers from panic.
+// The middlewares are applied within the recovery.
 func (r *Router) MWErrorPanic(handle Handle) httprouter.Handle {
-	return r.MWError(func(w http.ResponseWriter, req *http.Request, p httprouter.Params) (err error) {
-		defer func() {
-			if e1 := recover(); e1 != nil {
-				err = r.mux.HandlePanic(err, e1)
-			}
-		}()
-		return handle(w, req, p)
-	})
+	return withContextParams(r.mux.MWErrorPanic(contextParams(r.chain(handle))))
+}
+
+// contextParams converts the handle to an ehttp.HandlerFunc reading the params from the request context.
+func contextParams(handle Handle) ehttp.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) error {
+		return handle(w, req, httprouter.ParamsFromContext(req.Context()))
+	}
+}
+
+// withContextParams converts the handler to an httprouter.Handle storing the params in the request context.
+func withContextParams(handler http.HandlerFunc) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+		handler(w, withParams(req, p))
+	}
+}
+
+// Buffered wraps the handle so its response is buffered up to limit bytes.
+// See ehttp.Buffered.
+func Buffered(limit int, handle Handle) Handle {
+	handler := ehttp.Buffered(limit, contextParams(handle))
+	return func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		return handler(w, withParams(req, p))
+	}
 }
 
 // MWError exposes the default router MWError method.
@@ -1,11 +1,19 @@
 package ehttprouter
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io/fs"
 	"io/ioutil"
+	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/creack/ehttp"
@@ -32,15 +40,22 @@ func TestMWErrorCommon(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	assertJSONError(t, "fail", string(body))
+	assertJSONError(t, "Internal Server Error", string(body))
 }
 
 func TestMWErrorPanicCommon(t *testing.T) {
+	var (
+		file string
+		name string
+		line int
+	)
 	hdlr := func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		name, file, line = getCallstack(0)
 		panic(fmt.Errorf("fail"))
 	}
+	buf := bytes.NewBuffer(nil)
 	router := httprouter.New()
-	router.GET("/", MWErrorPanic(hdlr))
+	router.GET("/", NewWithOptions(ehttp.WithLogger(log.New(buf, "", 0))).MWErrorPanic(hdlr))
 
 	ts := httptest.NewServer(router)
 	defer ts.Close()
@@ -55,7 +70,11 @@ func TestMWErrorPanicCommon(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	assertJSONError(t, "fail", string(body))
+	// The internal error is not sent to the client, but logged.
+	assertJSONError(t, "Internal Server Error", string(body))
+	if expect := fmt.Sprintf("error=\"[%s %s:%d] fail\"", name, file, line+1); !strings.Contains(buf.String(), expect) {
+		t.Errorf("Panic error not found in log output.\nExpect:\t%s\nGot:\t%s", expect, buf)
+	}
 }
 
 func TestMWErrorPanicEHTTP(t *testing.T) {
@@ -82,11 +101,18 @@ func TestMWErrorPanicEHTTP(t *testing.T) {
 }
 
 func TestMWErrorPanicInt(t *testing.T) {
+	var (
+		file string
+		name string
+		line int
+	)
 	hdlr := func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		name, file, line = getCallstack(0)
 		panic(http.StatusTeapot)
 	}
+	buf := bytes.NewBuffer(nil)
 	router := httprouter.New()
-	router.GET("/", MWErrorPanic(hdlr))
+	router.GET("/", NewWithOptions(ehttp.WithLogger(log.New(buf, "", 0))).MWErrorPanic(hdlr))
 
 	ts := httptest.NewServer(router)
 	defer ts.Close()
@@ -101,7 +127,11 @@ func TestMWErrorPanicInt(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	assertJSONError(t, fmt.Sprintf("(int) %d", http.StatusTeapot), string(body))
+	// The internal error is not sent to the client, but logged.
+	assertJSONError(t, "Internal Server Error", string(body))
+	if expect := fmt.Sprintf("error=\"[%s %s:%d] (int) %d\"", name, file, line+1, http.StatusTeapot); !strings.Contains(buf.String(), expect) {
+		t.Errorf("Panic error not found in log output.\nExpect:\t%s\nGot:\t%s", expect, buf)
+	}
 }
 
 func TestWrappedHelperMethods(t *testing.T) {
@@ -175,10 +205,93 @@ func TestWrappedHelperMethods(t *testing.T) {
 	}
 }
 
-func TestDummyPanicHandler(t *testing.T) {
-	router := New(nil, "", false, nil)
-	// No-op. Makeing sure that it is a function and not a field.
-	router.PanicHandler()
+func TestRouterErrors(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	router := NewWithOptions(ehttp.WithPanicRecovery(true), ehttp.WithLogger(log.New(buf, "", 0)))
+	router.GET("/a", func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		return nil
+	})
+	router.PUT("/a", func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		return nil
+	})
+	router.Router.Handler("GET", "/b", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		panic(ehttp.NewErrorf(http.StatusTeapot, "fail"))
+	}))
+
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		status int
+		body   string
+	}{
+		{method: "GET", path: "/unknown", status: http.StatusNotFound, body: "Not Found"},
+		{method: "POST", path: "/a", status: http.StatusMethodNotAllowed, body: "Method Not Allowed"},
+		{method: "GET", path: "/b", status: http.StatusTeapot, body: "fail"},
+	} {
+		req, err := http.NewRequest(tc.method, ts.URL+tc.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Error connecting to test server: %s", err)
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Error reading body: %s", err)
+		}
+		assertInt(t, tc.status, resp.StatusCode)
+		assertString(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+		if !strings.Contains(string(body), tc.body) {
+			t.Errorf("Unexpected body for %s %s.\nExpect:\t%s\nGot:\t%s", tc.method, tc.path, tc.body, body)
+		}
+		if tc.status == http.StatusMethodNotAllowed {
+			assertString(t, "GET, OPTIONS, PUT", resp.Header.Get("Allow"))
+		}
+	}
+	if !strings.Contains(buf.String(), "Handler panic recovered") {
+		t.Errorf("Panic not found in log output: %s", buf)
+	}
+}
+
+func TestRouterPanicAfterWrite(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	router := NewWithOptions(ehttp.WithPanicRecovery(true), ehttp.WithLogger(log.New(buf, "", 0)))
+	router.HandlerFunc("GET", "/partial", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("partial"))
+		panic("fail")
+	})
+
+	srvLog := bytes.NewBuffer(nil)
+	ts := httptest.NewUnstartedServer(router)
+	ts.Config.ErrorLog = log.New(srvLog, "", 0)
+	ts.Start()
+
+	resp, err := http.Get(ts.URL + "/partial")
+	if err != nil {
+		t.Fatalf("Error connecting to test server: %s", err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Error reading body: %s", err)
+	}
+	// The headers were sent by the handler, the panic is only logged.
+	assertInt(t, http.StatusOK, resp.StatusCode)
+	assertString(t, "partial", string(body))
+	for _, expect := range []string{"Handler panic recovered", "HTTP Error (header already sent)"} {
+		if !strings.Contains(buf.String(), expect) {
+			t.Errorf("%q not found in log output: %s", expect, buf)
+		}
+	}
+	ts.Close()
+	if strings.Contains(srvLog.String(), "superfluous") {
+		t.Errorf("Unexpected WriteHeader call: %s", srvLog)
+	}
 }
 
 func TestWrappedHandleMethods(t *testing.T) {
@@ -235,3 +348,463 @@ func TestWrappedSpecialMethods(t *testing.T) {
 	// Dummy call for coverage. Already tested in httprouter package.
 	router.ServeFiles("/f/*filepath", nil)
 }
+
+func TestProblemDetails(t *testing.T) {
+	router := New(ehttp.EncodeProblem, ehttp.ProblemContentType, false, nil)
+	router.GET("/users/:id", func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		return ehttp.NotFound.WithExtension("id", p.ByName("id"))
+	})
+
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/users/abc")
+	if err != nil {
+		t.Fatalf("Error connecting to test server: %s", err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Error reading response from test server: %s", err)
+	}
+	assertInt(t, http.StatusNotFound, resp.StatusCode)
+	assertString(t, ehttp.ProblemContentType, resp.Header.Get("Content-Type"))
+	assertString(t, `{"detail":"Not Found","id":"abc","instance":"/users/abc","status":404,"title":"Not Found"}`, string(body))
+}
+
+func TestNewWithOptions(t *testing.T) {
+	router := NewWithOptions(ehttp.WithContentType("text/plain"), ehttp.WithPanicRecovery(true), ehttp.WithErrorEncoder(ehttp.EncodeText))
+	router.GET("/", func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		panic(ehttp.NewErrorf(http.StatusTeapot, "fail"))
+	})
+
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("Error connecting to test server: %s", err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Error reading response from test server: %s", err)
+	}
+	assertInt(t, http.StatusTeapot, resp.StatusCode)
+	assertString(t, "text/plain", resp.Header.Get("Content-Type"))
+	// The panic location is not sent to the client.
+	assertString(t, "fail\n", string(body))
+}
+
+func TestLogPattern(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	router := NewWithOptions(ehttp.WithLogHandler(slog.NewTextHandler(buf, nil)))
+	router.GET("/users/:id", func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		w.WriteHeader(http.StatusAccepted)
+		return ehttp.NotFound
+	})
+
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/users/abc")
+	if err != nil {
+		t.Fatalf("Error connecting to test server: %s", err)
+	}
+	_ = resp.Body.Close()
+	assertInt(t, http.StatusAccepted, resp.StatusCode)
+	for _, attr := range []string{"method=GET", "path=/users/abc", "pattern=/users/:id", "status=202", `error="Not Found"`} {
+		if !strings.Contains(buf.String(), attr) {
+			t.Errorf("%s not found in log output.\nGot: %s", attr, buf.String())
+		}
+	}
+}
+
+func TestHooks(t *testing.T) {
+	var panics []int
+	router := NewWithOptions(ehttp.WithPanicRecovery(true), ehttp.WithHooks(ehttp.Hooks{
+		OnPanic: func(w ehttp.ResponseWriter, req *http.Request, err error, status int) {
+			panics = append(panics, status)
+		},
+	}))
+	router.GET("/", func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		panic(ehttp.NewErrorf(http.StatusTeapot, "fail"))
+	})
+
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("Error connecting to test server: %s", err)
+	}
+	_ = resp.Body.Close()
+	assertInt(t, 1, len(panics))
+	assertInt(t, http.StatusTeapot, panics[0])
+}
+
+func TestBuffered(t *testing.T) {
+	router := NewWithOptions(ehttp.WithErrorEncoder(ehttp.EncodeText))
+	router.GET("/", Buffered(1024, func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		fmt.Fprintf(w, "partial")
+		return ehttp.NewErrorf(http.StatusTeapot, "fail")
+	}))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assertInt(t, http.StatusTeapot, rec.Code)
+	assertString(t, "fail", rec.Body.String())
+}
+
+func TestUse(t *testing.T) {
+	var trace []string
+	router := NewWithOptions(ehttp.WithPanicRecovery(true), ehttp.WithErrorEncoder(ehttp.EncodeText))
+	router.Use(func(next Handle) Handle {
+		return func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+			trace = append(trace, "router:"+p.ByName("id"))
+			if err := next(w, req, p); err != nil {
+				return ehttp.NewErrorf(http.StatusTeapot, "wrapped: %s", err)
+			}
+			return nil
+		}
+	}, Adapt(func(next ehttp.HandlerFunc) ehttp.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			trace = append(trace, "adapted")
+			return next(w, req)
+		}
+	}))
+	router.GET("/users/:id", func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		trace = append(trace, "handle")
+		return fmt.Errorf("fail")
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/users/abc", nil))
+	assertInt(t, http.StatusTeapot, rec.Code)
+	assertString(t, "wrapped: fail\n", rec.Body.String())
+	assertString(t, "router:abc,adapted,handle", strings.Join(trace, ","))
+}
+
+func TestMiddlewaresAppliedOnce(t *testing.T) {
+	for _, recoverPanic := range []bool{false, true} {
+		calls := map[string]int{}
+		counter := func(name string) ehttp.Middleware {
+			return func(next ehttp.HandlerFunc) ehttp.HandlerFunc {
+				calls[name]++
+				return next
+			}
+		}
+		router := NewWithOptions(ehttp.WithPanicRecovery(recoverPanic), ehttp.WithBuffering(1024))
+		router.mux.Use(counter("mux"))
+		router.Use(Adapt(counter("router")))
+		router.GET("/users/:id", Buffered(1024, func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+			return ehttp.NewErrorf(http.StatusTeapot, "fail %s", p.ByName("id"))
+		}))
+
+		for i := 0; i < 3; i++ {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest("GET", fmt.Sprintf("/users/%d", i), nil))
+			assertInt(t, http.StatusTeapot, rec.Code)
+			assertJSONError(t, fmt.Sprintf("fail %d", i), rec.Body.String())
+		}
+		assertInt(t, 1, calls["mux"])
+		assertInt(t, 1, calls["router"])
+	}
+}
+
+func TestGroup(t *testing.T) {
+	var trace []string
+	traceMW := func(name string) Middleware {
+		return func(next Handle) Handle {
+			return func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+				trace = append(trace, name)
+				return next(w, req, p)
+			}
+		}
+	}
+	router := NewWithOptions(ehttp.WithErrorEncoder(ehttp.EncodeText), ehttp.WithContentType("text/plain"))
+	router.Use(traceMW("root"))
+	public := router.Group("/public/")
+	public.GET("/", func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		panic("unreachable")
+	})
+	admin := router.Group("/admin", traceMW("admin")).With(ehttp.WithProblemDetails(), ehttp.WithPanicRecovery(true))
+	v1 := admin.Group("/v1", traceMW("v1"))
+	v1.GET("/users/:id", func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		panic(ehttp.NewErrorf(http.StatusTeapot, "fail %s", p.ByName("id")))
+	})
+	router.GET("/", func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		return ehttp.NewErrorf(http.StatusTeapot, "fail")
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/v1/users/abc", nil))
+	assertInt(t, http.StatusTeapot, rec.Code)
+	assertString(t, ehttp.ProblemContentType, rec.Header().Get("Content-Type"))
+	assertString(t, "root,admin,v1", strings.Join(trace, ","))
+	if !strings.Contains(rec.Body.String(), "fail abc") {
+		t.Errorf("Unexpected body: %s", rec.Body)
+	}
+
+	trace = nil
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assertInt(t, http.StatusTeapot, rec.Code)
+	assertString(t, "text/plain", rec.Header().Get("Content-Type"))
+	assertString(t, "fail\n", rec.Body.String())
+	assertString(t, "root", strings.Join(trace, ","))
+
+	// Without panic recovery, the panic is not handled.
+	func() {
+		defer func() {
+			if e1 := recover(); e1 != "unreachable" {
+				t.Errorf("Unexpected panic: %v", e1)
+			}
+		}()
+		public.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/public/", nil))
+	}()
+
+	func() {
+		defer func() {
+			if e1 := recover(); e1 == nil {
+				t.Errorf("Invalid group prefix should panic")
+			}
+		}()
+		router.Group("admin")
+	}()
+}
+
+func TestGroupRouterErrors(t *testing.T) {
+	router := NewWithOptions(ehttp.WithPanicRecovery(true))
+	admin := router.Group("/admin").With(ehttp.WithProblemDetails())
+	admin.GET("/users", func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		return nil
+	})
+	admin.Group("/v1").HandlerFunc("GET", "/panic", func(w http.ResponseWriter, req *http.Request) {
+		panic(ehttp.NewErrorf(http.StatusTeapot, "fail"))
+	})
+	raw := router.Group("/raw").With(ehttp.WithPanicRecovery(false))
+	raw.HandlerFunc("GET", "/panic", func(w http.ResponseWriter, req *http.Request) {
+		panic("raw")
+	})
+	router.GET("/users", func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		return nil
+	})
+
+	for _, tc := range []struct {
+		method      string
+		path        string
+		status      int
+		contentType string
+	}{
+		{method: "GET", path: "/nope", status: http.StatusNotFound, contentType: "application/json; charset=utf-8"},
+		{method: "GET", path: "/administrator", status: http.StatusNotFound, contentType: "application/json; charset=utf-8"},
+		{method: "POST", path: "/users", status: http.StatusMethodNotAllowed, contentType: "application/json; charset=utf-8"},
+		{method: "GET", path: "/admin/nope", status: http.StatusNotFound, contentType: ehttp.ProblemContentType},
+		{method: "POST", path: "/admin/users", status: http.StatusMethodNotAllowed, contentType: ehttp.ProblemContentType},
+		{method: "GET", path: "/admin/v1/panic", status: http.StatusTeapot, contentType: ehttp.ProblemContentType},
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		assertInt(t, tc.status, rec.Code)
+		assertString(t, tc.contentType, rec.Header().Get("Content-Type"))
+	}
+
+	// The group without panic recovery propagates the panics of the standard handlers.
+	func() {
+		defer func() {
+			if e1 := recover(); e1 != "raw" {
+				t.Errorf("Unexpected panic: %v", e1)
+			}
+		}()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/raw/panic", nil))
+	}()
+
+	// A group can enable panic recovery on a router without it.
+	router = NewWithOptions()
+	router.Group("/safe").With(ehttp.WithPanicRecovery(true)).HandlerFunc("GET", "/panic", func(w http.ResponseWriter, req *http.Request) {
+		panic("fail")
+	})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/safe/panic", nil))
+	assertInt(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestHandleFuncHTTP(t *testing.T) {
+	router := NewWithOptions(ehttp.WithPanicRecovery(true), ehttp.WithErrorEncoder(ehttp.EncodeText), ehttp.WithLogHandler(slog.NewTextHandler(ioutil.Discard, nil)))
+	router.HandleFunc("GET", "/users/:id", func(w http.ResponseWriter, req *http.Request) error {
+		return ehttp.NewErrorf(http.StatusTeapot, "fail %s", httprouter.ParamsFromContext(req.Context()).ByName("id"))
+	})
+	router.HandleHTTP("GET", "/panic/:id", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		panic(ehttp.NewErrorf(http.StatusConflict, "panic %s", httprouter.ParamsFromContext(req.Context()).ByName("id")))
+	}))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/users/abc", nil))
+	assertInt(t, http.StatusTeapot, rec.Code)
+	assertString(t, "fail abc\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/panic/def", nil))
+	assertInt(t, http.StatusConflict, rec.Code)
+	if !strings.HasSuffix(rec.Body.String(), "panic def\n") {
+		t.Errorf("Unexpected body: %s", rec.Body)
+	}
+}
+
+// permissionFS is a file system denying access to the "secret" file.
+type permissionFS struct {
+	http.FileSystem
+}
+
+func (fsys permissionFS) Open(name string) (http.File, error) {
+	if name == "/secret" {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	return fsys.FileSystem.Open(name)
+}
+
+func TestServeFiles(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	router := NewWithOptions(ehttp.WithLogger(log.New(buf, "", 0)))
+	router.AddHooks(ehttp.Hooks{OnError: func(w ehttp.ResponseWriter, req *http.Request, err error, status int) {
+		buf.WriteString(err.Error())
+	}})
+	router.ServeFiles("/files/*filepath", permissionFS{http.FS(fstest.MapFS{
+		"hello.txt": &fstest.MapFile{Data: []byte("hello")},
+		"secret":    &fstest.MapFile{Data: []byte("secret")},
+	})})
+
+	for _, tc := range []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{path: "/files/hello.txt", status: http.StatusOK, body: "hello"},
+		{path: "/files/missing.txt", status: http.StatusNotFound, body: `{"errors":["Not Found"]}` + "\n"},
+		{path: "/files/secret", status: http.StatusForbidden, body: `{"errors":["Forbidden"]}` + "\n"},
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", tc.path, nil))
+		assertInt(t, tc.status, rec.Code)
+		assertString(t, tc.body, rec.Body.String())
+	}
+	// The underlying error is kept server side.
+	if !strings.Contains(buf.String(), "open /secret: permission denied") {
+		t.Errorf("Unexpected error: %s", buf)
+	}
+
+	func() {
+		defer func() {
+			if e1 := recover(); e1 == nil {
+				t.Errorf("Invalid path should panic")
+			}
+		}()
+		router.ServeFiles("/files", nil)
+	}()
+}
+
+func TestJSON(t *testing.T) {
+	type user struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	router := NewWithOptions()
+	router.PUT("/users/:id", JSON(func(ctx context.Context, u user, p httprouter.Params) (user, error) {
+		if u.Name == "" {
+			return u, ehttp.NewErrorf(http.StatusUnprocessableEntity, "missing name")
+		}
+		u.ID = p.ByName("id")
+		return u, nil
+	}))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("PUT", "/users/42", strings.NewReader(`{"name":"bob"}`)))
+	assertInt(t, http.StatusOK, rec.Code)
+	assertString(t, `{"id":"42","name":"bob"}`+"\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("PUT", "/users/42", strings.NewReader(`{"name":"bob","role":"admin"}`)))
+	assertInt(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestRequestID(t *testing.T) {
+	var handleID string
+	router := NewWithOptions(ehttp.WithRequestID(), ehttp.WithErrorEncoder(ehttp.EncodeText))
+	router.GET("/users/:id", func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		handleID = ehttp.RequestIDFrom(req.Context())
+		return ehttp.NotFound
+	})
+
+	for _, path := range []string{"/users/abc", "/unknown"} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set(ehttp.RequestIDHeader, "abc-123")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assertInt(t, http.StatusNotFound, rec.Code)
+		assertString(t, "abc-123", rec.Header().Get(ehttp.RequestIDHeader))
+		assertString(t, "Not Found\nrequest id: abc-123\n", rec.Body.String())
+	}
+	assertString(t, "abc-123", handleID)
+}
+
+func TestAccessLog(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	router := NewWithOptions(ehttp.WithAccessLog(buf, ehttp.CommonLog))
+	router.GET("/users/:id", func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		fmt.Fprintf(w, "user %s", p.ByName("id"))
+		return nil
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/abc", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/users/abc", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/unknown", nil))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assertInt(t, 3, len(lines))
+	for i, expect := range []string{
+		`"GET /users/abc HTTP/1.1" 200 8`,
+		`"POST /users/abc HTTP/1.1" 405 34`,
+		`"GET /unknown HTTP/1.1" 404 25`,
+	} {
+		if !strings.HasSuffix(lines[i], expect) {
+			t.Errorf("Unexpected access log line.\nExpect:\t%s\nGot:\t%s", expect, lines[i])
+		}
+	}
+}
+
+func TestAccessLogStandardHandler(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	router := NewWithOptions(ehttp.WithAccessLog(buf, ehttp.JSONLog), ehttp.WithPanicRecovery(true))
+	router.HandlerFunc("GET", "/std", func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, "std")
+	})
+	router.HandlerFunc("GET", "/panic", func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		panic("fail")
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/std", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/panic", nil))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assertInt(t, 2, len(lines))
+	var entries [2]struct {
+		Path      string  `json:"path"`
+		Status    int     `json:"status"`
+		Bytes     int64   `json:"bytes"`
+		LatencyMS float64 `json:"latency_ms"`
+		Error     string  `json:"error"`
+	}
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &entries[i]); err != nil {
+			t.Fatalf("Error parsing access log: %s", err)
+		}
+	}
+	assertString(t, "/std", entries[0].Path)
+	assertInt(t, http.StatusOK, entries[0].Status)
+	assertInt(t, 3, int(entries[0].Bytes))
+	assertString(t, "/panic", entries[1].Path)
+	assertInt(t, http.StatusInternalServerError, entries[1].Status)
+	if entries[1].LatencyMS < 20 || entries[1].Error == "" {
+		t.Errorf("Unexpected access log for the recovered panic: %s", lines[1])
+	}
+}
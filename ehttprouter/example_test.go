@@ -26,3 +26,12 @@ func Example_panic() {
 	router.GET("/", ehttprouter.MWErrorPanic(hdlr))
 	log.Fatal(http.ListenAndServe(":8080", router))
 }
+
+func Example_options() {
+	hdlr := func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		return ehttp.NewErrorf(http.StatusTeapot, "fail")
+	}
+	router := ehttprouter.NewWithOptions(ehttp.WithProblemDetails(), ehttp.WithPanicRecovery(true))
+	router.GET("/", hdlr)
+	log.Fatal(http.ListenAndServe(":8080", router))
+}
@@ -0,0 +1,20 @@
+package ehttprouter
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/creack/ehttp"
+	"github.com/julienschmidt/httprouter"
+)
+
+// JSON converts the typed function to a Handle. See ehttp.JSON.
+// The handler is built once, the params are passed through the request context.
+func JSON[Req, Resp any](fn func(context.Context, Req, httprouter.Params) (Resp, error), opts ...ehttp.JSONOption) Handle {
+	handler := ehttp.JSON(func(ctx context.Context, in Req) (Resp, error) {
+		return fn(ctx, in, httprouter.ParamsFromContext(ctx))
+	}, opts...)
+	return func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		return handler(w, withParams(req, p))
+	}
+}
@@ -0,0 +1,49 @@
+package ehttprouter
+
+import (
+	"net/http"
+
+	"github.com/creack/ehttp"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Middleware wraps a Handle. See ehttp.Middleware.
+type Middleware func(Handle) Handle
+
+// Chain composes the given middlewares into a single one.
+// The first middleware is the outermost one.
+func Chain(mws ...Middleware) Middleware {
+	return func(handle Handle) Handle {
+		for i := len(mws) - 1; i >= 0; i-- {
+			handle = mws[i](handle)
+		}
+		return handle
+	}
+}
+
+// Adapt converts an ehttp.Middleware to a Middleware.
+// The middleware is applied once, the params are passed through the request context to the inner handle.
+func Adapt(mw ehttp.Middleware) Middleware {
+	return func(next Handle) Handle {
+		handler := mw(contextParams(next))
+		return func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+			return handler(w, withParams(req, p))
+		}
+	}
+}
+
+// Use appends the given middlewares to the Router.
+// The middlewares are applied when the handles are registered: Use is expected to be called
+// before registering the handles and is not safe for concurrent use.
+// The middlewares registered on the underlying ehttp.ServeMux are applied around them.
+func (r *Router) Use(mws ...Middleware) {
+	r.middlewares = append(r.middlewares, mws...)
+}
+
+// chain applies the Router middlewares to the given handle.
+func (r *Router) chain(handle Handle) Handle {
+	if len(r.middlewares) == 0 {
+		return handle
+	}
+	return Chain(r.middlewares...)(handle)
+}
@@ -1,54 +1,104 @@
 package ehttprouter
 
 import (
+	"context"
+	"errors"
+	"io/fs"
 	"net/http"
+	"path"
 
+	"github.com/creack/ehttp"
 	"github.com/julienschmidt/httprouter"
 )
 
 // ServeHTTP exposes the underlying router's http.Handler interface.
+// The handlers and the httprouter hooks are given an ehttp.ResponseWriter, so the errors
+// of the PanicHandler are handled as late errors when the handler already sent the headers.
+// Every request is logged once served, if the access logs of the mux owning the path are enabled.
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	r.Router.ServeHTTP(w, req)
+	r.errorMuxes.lookup(req.URL.Path).ServeAccessLog(ehttp.NewResponseWriter(w), req, r.Router)
 }
 
 // Handle wraps the httprouter Handle.
+// The path is prefixed with the group prefix.
 func (r *Router) Handle(method, path string, handle Handle) {
-	r.Router.Handle(method, path, r.middlewareSelect(handle))
+	path = r.prefix + path
+	r.Router.Handle(method, path, r.middlewareSelect(path, handle))
 }
 
 // DELETE wraps underlying method.
-func (r *Router) DELETE(path string, handle Handle) { r.Router.DELETE(path, r.middlewareSelect(handle)) }
+func (r *Router) DELETE(path string, handle Handle) {
+	r.Handle(http.MethodDelete, path, handle)
+}
 
 // GET wraps underlying method.
-func (r *Router) GET(path string, handle Handle) { r.Router.GET(path, r.middlewareSelect(handle)) }
+func (r *Router) GET(path string, handle Handle) {
+	r.Handle(http.MethodGet, path, handle)
+}
 
 // HEAD wraps underlying method.
-func (r *Router) HEAD(path string, handle Handle) { r.Router.HEAD(path, r.middlewareSelect(handle)) }
+func (r *Router) HEAD(path string, handle Handle) {
+	r.Handle(http.MethodHead, path, handle)
+}
 
 // OPTIONS wraps underlying method.
 func (r *Router) OPTIONS(path string, handle Handle) {
-	r.Router.OPTIONS(path, r.middlewareSelect(handle))
+	r.Handle(http.MethodOptions, path, handle)
 }
 
 // PATCH wraps underlying method.
-func (r *Router) PATCH(path string, handle Handle) { r.Router.PATCH(path, r.middlewareSelect(handle)) }
+func (r *Router) PATCH(path string, handle Handle) {
+	r.Handle(http.MethodPatch, path, handle)
+}
 
 // POST wraps underlying method.
-func (r *Router) POST(path string, handle Handle) { r.Router.POST(path, r.middlewareSelect(handle)) }
+func (r *Router) POST(path string, handle Handle) {
+	r.Handle(http.MethodPost, path, handle)
+}
 
 // PUT wraps underlying method.
-func (r *Router) PUT(path string, handle Handle) { r.Router.PUT(path, r.middlewareSelect(handle)) }
+func (r *Router) PUT(path string, handle Handle) {
+	r.Handle(http.MethodPut, path, handle)
+}
 
 // Handler exposes the httprouter Handler method.
-// NOTE: does not handle erors nor panics.
+// NOTE: does not go through the error middleware, see HandleHTTP.
+// If the recoverPanic flag is set, the panics are sent by the PanicHandler, or logged if the headers were already sent.
 func (r *Router) Handler(method string, path string, handler http.Handler) {
-	r.Router.Handler(method, path, handler)
+	r.Router.Handler(method, r.prefix+path, handler)
 }
 
 // HandlerFunc exposes the httprouter HandlerFunc method.
-// NOTE: does not handle erors nor panics.
+// NOTE: does not go through the error middleware, see HandleHTTP.
+// If the recoverPanic flag is set, the panics are sent by the PanicHandler, or logged if the headers were already sent.
 func (r *Router) HandlerFunc(method string, path string, handler http.HandlerFunc) {
-	r.Router.HandlerFunc(method, path, handler)
+	r.Router.HandlerFunc(method, r.prefix+path, handler)
+}
+
+// HandleFunc registers the ehttp.HandlerFunc with the error middleware.
+// The params are available in the request context, see httprouter.ParamsFromContext.
+func (r *Router) HandleFunc(method, path string, handler ehttp.HandlerFunc) {
+	r.Handle(method, path, func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		return handler(w, withParams(req, p))
+	})
+}
+
+// HandleHTTP registers the standard http.Handler with the error middleware,
+// so panics are recovered if the recoverPanic flag is set.
+// The params are available in the request context, see httprouter.ParamsFromContext.
+func (r *Router) HandleHTTP(method, path string, handler http.Handler) {
+	r.Handle(method, path, func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		handler.ServeHTTP(w, withParams(req, p))
+		return nil
+	})
+}
+
+// withParams stores the params in the request context like the httprouter Handler method.
+func withParams(req *http.Request, p httprouter.Params) *http.Request {
+	if len(p) == 0 {
+		return req
+	}
+	return req.WithContext(context.WithValue(req.Context(), httprouter.ParamsKey, p))
 }
 
 // Lookup exposes the httprouter Lookup method.
@@ -57,8 +107,40 @@ func (r *Router) Lookup(method string, path string) (httprouter.Handle, httprout
 	return r.Router.Lookup(method, path)
 }
 
-// ServeFiles exposes the httprouter ServeFiles method.
-// NOTE: does not handle errors nor panics.
+// ServeFiles serves the files from the given file system, like the httprouter ServeFiles method.
+// The path must end with "/*filepath".
+// Missing files are sent as 404 and permission errors as 403 via the error middleware.
 func (r *Router) ServeFiles(path string, root http.FileSystem) {
-	r.Router.ServeFiles(path, root)
+	if len(path) < 10 || path[len(path)-10:] != "/*filepath" {
+		panic("path must end with /*filepath in path '" + path + "'")
+	}
+
+	fileServer := http.FileServer(root)
+
+	r.GET(path, func(w http.ResponseWriter, req *http.Request, p httprouter.Params) error {
+		name := p.ByName("filepath")
+		if err := checkFile(root, name); err != nil {
+			return err
+		}
+		req.URL.Path = name
+		fileServer.ServeHTTP(w, req)
+		return nil
+	})
+}
+
+// checkFile makes sure the named file can be opened from the file system.
+// fs.ErrNotExist and fs.ErrPermission are converted to 404 and 403 errors.
+// The file system error is kept for the logs but not sent to the client.
+func checkFile(root http.FileSystem, name string) error {
+	f, err := root.Open(path.Clean("/" + name))
+	switch {
+	case err == nil:
+		return f.Close()
+	case errors.Is(err, fs.ErrNotExist):
+		return ehttp.NewPublicError(http.StatusNotFound, http.StatusText(http.StatusNotFound), err)
+	case errors.Is(err, fs.ErrPermission):
+		return ehttp.NewPublicError(http.StatusForbidden, http.StatusText(http.StatusForbidden), err)
+	default:
+		return err
+	}
 }
@@ -0,0 +1,67 @@
+package ehttp
+
+import (
+	"encoding/xml"
+	"html/template"
+	"net/http"
+)
+
+// XMLError is the struct sent to the client by EncodeXML.
+type XMLError struct {
+	XMLName   xml.Name `xml:"errors"`
+	Code      string   `xml:"code,attr,omitempty"`       // Application error code, see RegisterCode.
+	RequestID string   `xml:"request_id,attr,omitempty"` // Request ID, see WithRequestID.
+	Errors    []string `xml:"error"`
+}
+
+// EncodeXML is an ErrorEncoder sending the public messages of the error wrapped in a XMLError.
+func EncodeXML(w ResponseWriter, req *http.Request, err error) {
+	_ = xml.NewEncoder(w).Encode(&XMLError{Code: CodeOf(err), RequestID: requestIDFromRequest(req), Errors: PublicMessages(err)})
+}
+
+// ErrorPage is the data passed to the HTMLEncoder template.
+type ErrorPage struct {
+	Status     int    // Http status code.
+	StatusText string // Http status text.
+	Message    string // Public message of the error.
+	Code       string // Application error code, if any.
+	RequestID  string // Request ID, if any. See WithRequestID.
+}
+
+// defaultErrorPage is the template used by HTMLEncoder when none is given.
+var defaultErrorPage = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head><title>{{.Status}} {{.StatusText}}</title></head>
+<body>
+<h1>{{.Status}} {{.StatusText}}</h1>
+<p>{{.Message}}</p>
+{{- if .Code}}
+<p><code>{{.Code}}</code></p>
+{{- end}}
+{{- if .RequestID}}
+<p>Request ID: <code>{{.RequestID}}</code></p>
+{{- end}}
+</body>
+</html>
+`))
+
+// HTMLEncoder creates an ErrorEncoder rendering the given template with an ErrorPage.
+// Uses a minimal error page if the template is nil.
+func HTMLEncoder(tmpl *template.Template) ErrorEncoder {
+	if tmpl == nil {
+		tmpl = defaultErrorPage
+	}
+	return func(w ResponseWriter, req *http.Request, err error) {
+		status := w.Code()
+		if status == 0 {
+			status = statusCode(err)
+		}
+		_ = tmpl.Execute(w, ErrorPage{
+			Status:     status,
+			StatusText: http.StatusText(status),
+			Message:    PublicMessage(err),
+			Code:       CodeOf(err),
+			RequestID:  requestIDFromRequest(req),
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package ehttp
+
+import (
+	"encoding/xml"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEncodeXML(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewResponseWriter(rec)
+	w.WriteHeader(http.StatusInternalServerError)
+	EncodeXML(w, nil, NewErrorf(http.StatusInternalServerError, "db failure"))
+
+	xmlErr := XMLError{}
+	if err := xml.Unmarshal(rec.Body.Bytes(), &xmlErr); err != nil {
+		t.Fatalf("Error parsing xml error: %s", err)
+	}
+	assertInt(t, 1, len(xmlErr.Errors))
+	assertString(t, "Internal Server Error", xmlErr.Errors[0])
+}
+
+func TestHTMLEncoder(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewResponseWriter(rec)
+	w.WriteHeader(http.StatusNotFound)
+	HTMLEncoder(template.Must(template.New("").Parse(`{{.Status}}|{{.StatusText}}|{{.Message}}`)))(w, nil, NotFound.WithPublic("<unknown> user"))
+	assertString(t, "404|Not Found|&lt;unknown&gt; user", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	HTMLEncoder(nil)(NewResponseWriter(rec), nil, BadRequest)
+	assertString(t, `<!DOCTYPE html>
+<html>
+<head><title>400 Bad Request</title></head>
+<body>
+<h1>400 Bad Request</h1>
+<p>Bad Request</p>
+</body>
+</html>`, rec.Body.String())
+}
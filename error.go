@@ -1,22 +1,38 @@
 package ehttp
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
-)
-
-// Common errors.
-var (
-	InternalServerError = NewErrorf(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-	BadRequest          = NewErrorf(http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
-	Unauthorized        = NewErrorf(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
-	NotFound            = NewErrorf(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+	"strings"
 )
 
 // Error is a basic error including the http return code.
+// It can be enriched with RFC 9457 problem details (type, title, instance and extension members).
 type Error struct {
-	code  int
-	error error
+	code      int
+	error     error
+	public    string        // Client-safe message. See PublicMessage.
+	formatted bool          // Flag to know whether or not the message was formatted for the client (NewErrorf).
+	errorCode string        // Machine-readable application error code. See RegisterCode.
+	details   *errorDetails // Optional data. Kept behind a pointer so Error values stay comparable.
+}
+
+// errorDetails holds the optional data of an Error.
+type errorDetails struct {
+	header http.Header // Headers to send with the error.
+
+	problemType string                 // Problem type URI.
+	title       string                 // Short human-readable summary of the problem type.
+	instance    string                 // URI reference identifying the specific occurrence.
+	extensions  map[string]interface{} // Problem extension members.
+}
+
+// newStatusError creates a new http error using the status text as message.
+func newStatusError(code int) *Error {
+	return &Error{
+		code:  code,
+		error: errors.New(http.StatusText(code)),
+	}
 }
 
 // Code is an accessor for the error code.
@@ -37,18 +53,213 @@ func (e Error) GetError() error {
 	return e.error
 }
 
+// Unwrap exposes the underlying error to errors.Is / errors.As / errors.Unwrap.
+func (e Error) Unwrap() error {
+	return e.error
+}
+
+// Is reports whether the target is an ehttp error with the same http status code
+// or the StatusClass of the http status code.
+// If the target has an application error code, it must match as well.
+func (e Error) Is(target error) bool {
+	if statusClassIs(e.code, target) {
+		return true
+	}
+	t, ok := target.(*Error)
+	return ok && t != nil && t.code != 0 && t.code == e.code && (t.errorCode == "" || t.errorCode == e.errorCode)
+}
+
+// Public is an accessor for the client-safe message.
+func (e Error) Public() string {
+	return e.public
+}
+
+// Type is an accessor for the problem type URI.
+func (e Error) Type() string {
+	if e.details == nil {
+		return ""
+	}
+	return e.details.problemType
+}
+
+// Title is an accessor for the problem title.
+func (e Error) Title() string {
+	if e.details == nil {
+		return ""
+	}
+	return e.details.title
+}
+
+// Instance is an accessor for the problem instance URI.
+func (e Error) Instance() string {
+	if e.details == nil {
+		return ""
+	}
+	return e.details.instance
+}
+
+// Extensions is an accessor for the problem extension members.
+func (e Error) Extensions() map[string]interface{} {
+	if e.details == nil {
+		return nil
+	}
+	return e.details.extensions
+}
+
+// ErrorCode is an accessor for the application error code.
+func (e Error) ErrorCode() string {
+	return e.errorCode
+}
+
+// Header is an accessor for the headers to send with the error. Implements HeaderError.
+func (e Error) Header() http.Header {
+	if e.details == nil {
+		return nil
+	}
+	return e.details.header
+}
+
+// clone returns a copy of the error so the With* helpers never alter the original (i.e. the common errors).
+func (e *Error) clone() *Error {
+	e2 := *e
+	if e.details != nil {
+		d := *e.details
+		d.header = e.details.header.Clone()
+		if e.details.extensions != nil {
+			d.extensions = make(map[string]interface{}, len(e.details.extensions))
+			for k, v := range e.details.extensions {
+				d.extensions[k] = v
+			}
+		}
+		e2.details = &d
+	}
+	return &e2
+}
+
+// cloneDetails returns a copy of the error with its optional data allocated.
+func (e *Error) cloneDetails() *Error {
+	e2 := e.clone()
+	if e2.details == nil {
+		e2.details = &errorDetails{}
+	}
+	return e2
+}
+
+// WithErrorCode returns a copy of the error with the given application error code, e.g. "USER_NOT_FOUND".
+// See RegisterCode to declare the codes in the catalog.
+func (e *Error) WithErrorCode(code string) *Error {
+	e2 := e.clone()
+	e2.errorCode = code
+	return e2
+}
+
+// WithHeader returns a copy of the error with the given header to send, e.g. "Retry-After" or "WWW-Authenticate".
+// The header value is added to the existing ones.
+func (e *Error) WithHeader(key, value string) *Error {
+	e2 := e.cloneDetails()
+	if e2.details.header == nil {
+		e2.details.header = http.Header{}
+	}
+	e2.details.header.Add(key, value)
+	return e2
+}
+
+// WithPublic returns a copy of the error with the given client-safe message.
+func (e *Error) WithPublic(msg string) *Error {
+	e2 := e.clone()
+	e2.public = msg
+	return e2
+}
+
+// WithType returns a copy of the error with the given problem type URI.
+func (e *Error) WithType(uri string) *Error {
+	e2 := e.cloneDetails()
+	e2.details.problemType = uri
+	return e2
+}
+
+// WithTitle returns a copy of the error with the given problem title.
+func (e *Error) WithTitle(title string) *Error {
+	e2 := e.cloneDetails()
+	e2.details.title = title
+	return e2
+}
+
+// WithInstance returns a copy of the error with the given problem instance URI.
+func (e *Error) WithInstance(uri string) *Error {
+	e2 := e.cloneDetails()
+	e2.details.instance = uri
+	return e2
+}
+
+// WithExtension returns a copy of the error with the given problem extension member.
+func (e *Error) WithExtension(key string, value interface{}) *Error {
+	e2 := e.cloneDetails()
+	if e2.details.extensions == nil {
+		e2.details.extensions = map[string]interface{}{}
+	}
+	e2.details.extensions[key] = value
+	return e2
+}
+
 // NewErrorf creates a new http error including a status code.
+// For client errors (4xx), the formatted message is sent to the client.
 func NewErrorf(code int, f string, args ...interface{}) error {
-	return &Error{
-		code:  code,
-		error: fmt.Errorf(f, args...),
-	}
+	return newErrorf(code, f, args...)
 }
 
 // NewError creates a new http error including a status code.
+// The given error is the internal cause: it is not sent to the client, see PublicMessage.
 func NewError(code int, err error) error {
 	return &Error{
 		code:  code,
 		error: err,
 	}
 }
+
+// NewPublicError creates a new http error including a status code,
+// a client-safe message and the internal cause. Only the public message is sent
+// to the client, the cause is kept for the logs and hooks.
+func NewPublicError(code int, public string, err error) *Error {
+	return &Error{
+		code:   code,
+		error:  err,
+		public: public,
+	}
+}
+
+// PublicMessage returns the client-safe message for the given error:
+// - the public message of the first *Error in the error chain, if set,
+// - the http status text for server errors (5xx), so the internal cause is never sent to the client,
+// - for client errors (4xx), the message of the *Error created with NewErrorf (or the status constructors, i.e. BadRequestf)
+// or of the StatusCoder error (i.e. ValidationError), without the wrapping context,
+// - the http status text otherwise.
+// In debug mode (see WithDebug), the error message is always used.
+// The messages of multi errors (Errors and errors.Join) are joined with a newline.
+func PublicMessage(err error) string {
+	var de debugError
+	if errors.As(err, &de) {
+		return err.Error()
+	}
+	if _, ok := err.(multiError); ok {
+		return strings.Join(PublicMessages(err), "\n")
+	}
+	var e1 *Error
+	if errors.As(err, &e1) && e1.public != "" {
+		return e1.public
+	}
+	code := statusCode(err)
+	if code >= http.StatusInternalServerError {
+		return http.StatusText(code)
+	}
+	if e1 != nil && e1.formatted {
+		return e1.Error()
+	}
+	var sc StatusCoder
+	if errors.As(err, &sc) {
+		if e, ok := sc.(error); ok {
+			return e.Error()
+		}
+	}
+	return http.StatusText(code)
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -37,3 +38,94 @@ func TestGetError(t *testing.T) {
 	}
 
 }
+
+func TestErrorUnwrap(t *testing.T) {
+	e1 := NewError(http.StatusTeapot, io.EOF)
+	if !errors.Is(e1, io.EOF) {
+		t.Fatalf("errors.Is should find the underlying error")
+	}
+	if errors.Unwrap(e1) != io.EOF {
+		t.Fatalf("errors.Unwrap should return the underlying error")
+	}
+
+	var opError *net.OpError
+	if !errors.As(NewError(http.StatusTeapot, &net.OpError{Op: "op", Err: io.EOF}), &opError) {
+		t.Fatalf("errors.As should find the underlying error")
+	}
+	assertString(t, "op", opError.Op)
+}
+
+func TestErrorIsSentinel(t *testing.T) {
+	err := fmt.Errorf("loading user: %w", NewErrorf(http.StatusNotFound, "user not found"))
+	if !errors.Is(err, NotFound) {
+		t.Fatalf("errors.Is should match the sentinel with the same status code")
+	}
+	if errors.Is(err, BadRequest) {
+		t.Fatalf("errors.Is should not match a sentinel with a different status code")
+	}
+	if !errors.Is(errors.Join(io.EOF, fmt.Errorf("wrap: %w", Unauthorized)), Unauthorized) {
+		t.Fatalf("errors.Is should match the sentinel in a joined error")
+	}
+	if errors.Is(NewError(0, io.EOF), &Error{}) {
+		t.Fatalf("errors.Is should not match errors without status code")
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	assertInt(t, http.StatusInternalServerError, statusCode(io.EOF))
+	assertInt(t, http.StatusInternalServerError, statusCode(NewError(0, io.EOF)))
+	assertInt(t, http.StatusNotFound, statusCode(NotFound))
+	assertInt(t, http.StatusNotFound, statusCode(fmt.Errorf("wrap: %w", NotFound)))
+	assertInt(t, http.StatusTeapot, statusCode(errors.Join(io.EOF, fmt.Errorf("wrap: %w", NewError(http.StatusTeapot, io.EOF)))))
+}
+
+func TestPublicMessage(t *testing.T) {
+	dbErr := errors.New("pq: connection refused")
+
+	assertString(t, "Internal Server Error", PublicMessage(dbErr))
+	assertString(t, "Bad Gateway", PublicMessage(NewError(http.StatusBadGateway, dbErr)))
+	assertString(t, "fail", PublicMessage(NewErrorf(http.StatusTeapot, "fail")))
+	assertString(t, "fail", PublicMessage(fmt.Errorf("wrap: %w", NewErrorf(http.StatusTeapot, "fail"))))
+	assertString(t, "I'm a teapot", PublicMessage(fmt.Errorf("wrap: %w", NewError(http.StatusTeapot, dbErr))))
+	assertString(t, "validation failed: name: required", PublicMessage(fmt.Errorf("wrap: %w", NewValidationError(FieldError{Field: "name", Message: "required"}))))
+	assertString(t, "could not load user", PublicMessage(fmt.Errorf("wrap: %w", NewPublicError(http.StatusInternalServerError, "could not load user", dbErr))))
+	assertString(t, "unknown user", PublicMessage(NotFound.WithPublic("unknown user")))
+	assertString(t, "pq: connection refused", PublicMessage(debugError{NewPublicError(http.StatusInternalServerError, "could not load user", dbErr)}))
+
+	e1 := NewPublicError(http.StatusServiceUnavailable, "try again later", dbErr)
+	assertString(t, "pq: connection refused", e1.Error())
+	assertString(t, "try again later", e1.Public())
+	if !errors.Is(e1, dbErr) {
+		t.Fatal("The internal cause should be kept in the error chain")
+	}
+}
+
+func TestPublicMessageMux(t *testing.T) {
+	var internal error
+	mux := New(WithHooks(Hooks{OnError: func(w ResponseWriter, req *http.Request, err error, status int) {
+		internal = err
+	}}))
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
+		return NewPublicError(http.StatusInternalServerError, "could not load user", errors.New("pq: connection refused"))
+	})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assertInt(t, http.StatusInternalServerError, rec.Code)
+	assertJSONError(t, "could not load user", rec.Body.String())
+	assertString(t, "pq: connection refused", internal.Error())
+}
+
+func TestErrorComparable(t *testing.T) {
+	e1 := NotFound.WithType("https://example.com/probs/unknown-user")
+	e2 := *e1
+	if e2 != *e1 {
+		t.Fatal("Copies of an Error value should be equal")
+	}
+	if *NotFound == *e1 {
+		t.Fatal("Errors with different details should not be equal")
+	}
+	if NotFound.Type() != "" || NotFound.Header() != nil || NotFound.Extensions() != nil {
+		t.Fatal("The With helpers should not alter the original error")
+	}
+}
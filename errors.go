@@ -0,0 +1,171 @@
+package ehttp
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Errors is a collection of errors, sent to the client as several entries.
+// The errors created with errors.Join are sent the same way.
+//
+//	var errs ehttp.Errors
+//	for i, item := range items {
+//		if err := process(item); err != nil {
+//			errs = append(errs, fmt.Errorf("item %d: %w", i, err))
+//		}
+//	}
+//	return errs.Err()
+type Errors []error
+
+// Error implements the error interface. Joins the error messages with a newline.
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap exposes the errors to errors.Is and errors.As.
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// Err returns the collection if it has at least one error, nil otherwise.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// StatusPolicy resolves the http status code of a multi error from the status codes of its errors.
+type StatusPolicy func(codes []int) int
+
+// HighestStatus is a StatusPolicy using the highest status code.
+func HighestStatus(codes []int) int {
+	status := 0
+	for _, code := range codes {
+		if code > status {
+			status = code
+		}
+	}
+	return status
+}
+
+// FirstStatus is a StatusPolicy using the status code of the first error.
+func FirstStatus(codes []int) int {
+	if len(codes) == 0 {
+		return 0
+	}
+	return codes[0]
+}
+
+// MixedStatus is a StatusPolicy using the status code when all the errors share it.
+// Otherwise, uses http.StatusInternalServerError if any is a server error, http.StatusBadRequest if not.
+func MixedStatus(codes []int) int {
+	if len(codes) == 0 {
+		return 0
+	}
+	mixed, server := false, false
+	for _, code := range codes {
+		mixed = mixed || code != codes[0]
+		server = server || code >= http.StatusInternalServerError
+	}
+	switch {
+	case !mixed:
+		return codes[0]
+	case server:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// WithStatusPolicy sets the policy resolving the http status code of the multi errors.
+// When not set, the first ehttp error code found in the errors is used.
+func WithStatusPolicy(policy StatusPolicy) Option {
+	return func(sm *ServeMux) {
+		sm.statusPolicy = policy
+	}
+}
+
+// statusCode looks up the http status code to send for the given error using the status policy.
+func (sm *ServeMux) statusCode(err error) int {
+	if sm.statusPolicy == nil {
+		return statusCode(err)
+	}
+	errs := splitErrors(err)
+	if len(errs) < 2 {
+		return statusCode(err)
+	}
+	codes := make([]int, 0, len(errs))
+	for _, err := range errs {
+		codes = append(codes, statusCode(err))
+	}
+	if code := sm.statusPolicy(codes); code != 0 {
+		return code
+	}
+	return statusCode(err)
+}
+
+// multiError is implemented by the errors wrapping several errors, like Errors and errors.Join.
+type multiError interface {
+	Unwrap() []error
+}
+
+// walkErrors calls fn for each error of the error tree, depth first, until fn returns false.
+func walkErrors(err error, fn func(error) bool) bool {
+	if err == nil {
+		return true
+	}
+	if !fn(err) {
+		return false
+	}
+	switch e := err.(type) {
+	case multiError:
+		for _, err := range e.Unwrap() {
+			if !walkErrors(err, fn) {
+				return false
+			}
+		}
+	case interface{ Unwrap() error }:
+		return walkErrors(e.Unwrap(), fn)
+	}
+	return true
+}
+
+// splitErrors flattens the top-level multi errors. Other errors are returned as is.
+func splitErrors(err error) []error {
+	if de, ok := err.(debugError); ok {
+		errs := splitErrors(de.error)
+		for i, e := range errs {
+			errs[i] = debugError{e}
+		}
+		return errs
+	}
+	me, ok := err.(multiError)
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range me.Unwrap() {
+		if e != nil {
+			errs = append(errs, splitErrors(e)...)
+		}
+	}
+	return errs
+}
+
+// PublicMessages returns the client-safe messages of the given error.
+// Multi errors (Errors and errors.Join) yield one message per error, see PublicMessage.
+func PublicMessages(err error) []string {
+	errs := splitErrors(err)
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, PublicMessage(err))
+	}
+	return msgs
+}
@@ -0,0 +1,79 @@
+package ehttp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	var errs Errors
+	if err := errs.Err(); err != nil {
+		t.Fatalf("Empty errors should be nil, got: %v", err)
+	}
+	errs = append(errs, NotFound, nil, fmt.Errorf("item 1: %w", io.EOF))
+	err := errs.Err()
+	assertString(t, "Not Found\nitem 1: EOF", err.Error())
+	if !errors.Is(err, io.EOF) || !errors.Is(err, NotFound) {
+		t.Fatalf("Errors should be exposed to errors.Is")
+	}
+
+	// Nested multi errors are flattened, server errors stay internal.
+	err = errors.Join(errs, NotFound.WithPublic("missing item 2"))
+	assertInt(t, 3, len(PublicMessages(err)))
+	assertString(t, "Not Found\nInternal Server Error\nmissing item 2", PublicMessage(err))
+	assertString(t, "Not Found", PublicMessages(NotFound)[0])
+}
+
+func TestStatusPolicies(t *testing.T) {
+	for _, tc := range []struct {
+		codes   []int
+		highest int
+		first   int
+		mixed   int
+	}{
+		{codes: nil, highest: 0, first: 0, mixed: 0},
+		{codes: []int{404, 404}, highest: 404, first: 404, mixed: 404},
+		{codes: []int{404, 409}, highest: 409, first: 404, mixed: 400},
+		{codes: []int{404, 500, 409}, highest: 500, first: 404, mixed: 500},
+		{codes: []int{503, 503}, highest: 503, first: 503, mixed: 503},
+	} {
+		assertInt(t, tc.highest, HighestStatus(tc.codes))
+		assertInt(t, tc.first, FirstStatus(tc.codes))
+		assertInt(t, tc.mixed, MixedStatus(tc.codes))
+	}
+}
+
+func TestMultiErrorResponse(t *testing.T) {
+	hdlr := func(w http.ResponseWriter, req *http.Request) error {
+		return Errors{
+			fmt.Errorf("item 0: %w", NotFound),
+			NewErrorf(http.StatusConflict, "item 1: conflict"),
+		}
+	}
+	for _, tc := range []struct {
+		policy StatusPolicy
+		status int
+	}{
+		{policy: nil, status: http.StatusNotFound},
+		{policy: HighestStatus, status: http.StatusConflict},
+		{policy: MixedStatus, status: http.StatusBadRequest},
+	} {
+		mux := New(WithStatusPolicy(tc.policy))
+		mux.HandleFunc("/", hdlr)
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		assertInt(t, tc.status, rec.Code)
+		assertString(t, `{"errors":["Not Found","item 1: conflict"]}`+"\n", rec.Body.String())
+	}
+
+	rec := httptest.NewRecorder()
+	mux := New(WithProblemDetails(), WithStatusPolicy(HighestStatus))
+	mux.HandleFunc("/", hdlr)
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assertString(t, `{"detail":"Not Found\nitem 1: conflict","errors":["Not Found","item 1: conflict"],"instance":"/","status":409,"title":"Conflict"}`+"\n", rec.Body.String())
+}
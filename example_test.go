@@ -19,6 +19,18 @@ func ExampleServeMux() {
 	log.Fatal(http.ListenAndServe(":8080", mux))
 }
 
+func ExampleNew() {
+	mux := ehttp.New(
+		ehttp.WithProblemDetails(),
+		ehttp.WithPanicRecovery(true),
+		ehttp.WithLogger(log.New(os.Stderr, "", log.LstdFlags)),
+	)
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
+		return ehttp.NotFound
+	})
+	log.Fatal(http.ListenAndServe(":8080", mux))
+}
+
 func ExampleServeMux_panic() {
 	mux := ehttp.NewServeMux(nil, "application/text; charset=utf-8", true, nil)
 	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
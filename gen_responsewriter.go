@@ -0,0 +1,91 @@
+//go:build ignore
+
+// Generates responsewriter_gen.go, the combinations of the optional interfaces
+// the ehttp ResponseWriter and the buffered ResponseWriter expose depending on the underlying http.ResponseWriter.
+//
+// Usage: go generate.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"strings"
+)
+
+// optional lists the optional interfaces with the type implementing them.
+var optional = []struct {
+	iface string // Interface asserted on the underlying writer.
+	impl  string // Type exposing the interface, defined in responsewriter.go. Prefixed with "buffered" in buffer.go.
+}{
+	{"http.Flusher", "flusher"},
+	{"http.Hijacker", "hijacker"},
+	{"io.ReaderFrom", "readerFrom"},
+	{"http.Pusher", "pusher"},
+	{"http.CloseNotifier", "closeNotifier"},
+	{"io.StringWriter", "stringWriter"},
+}
+
+// wrappers lists the writers exposing the optional interfaces of their underlying writer.
+var wrappers = []struct {
+	fn         string // Name of the generated function.
+	typ        string // Type of the writer.
+	underlying string // Expression of the underlying writer.
+	prefix     string // Prefix of the types implementing the optional interfaces.
+}{
+	{"wrapResponseWriter", "responseWriter", "w.ResponseWriter", ""},
+	{"wrapBufferedResponseWriter", "bufferedResponseWriter", "w.w", "buffered"},
+}
+
+func main() {
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, `// Code generated by gen_responsewriter.go. DO NOT EDIT.
+
+package ehttp
+
+import (
+	"io"
+	"net/http"
+)
+`)
+	for _, wr := range wrappers {
+		fmt.Fprintf(buf, "\n// %s returns w exposing exactly the optional interfaces implemented by the underlying http.ResponseWriter.\n", wr.fn)
+		fmt.Fprintf(buf, "func %s(w *%s) ResponseWriter {\nvar mask int\n", wr.fn, wr.typ)
+		for i, o := range optional {
+			fmt.Fprintf(buf, "if _, ok := %s.(%s); ok {\nmask |= 1 << %d\n}\n", wr.underlying, o.iface, i)
+		}
+		fmt.Fprintf(buf, "switch mask {\n")
+		for mask := 0; mask < 1<<len(optional); mask++ {
+			var ifaces, fields, values []string
+			for i, o := range optional {
+				if mask&(1<<i) == 0 {
+					continue
+				}
+				impl := o.impl
+				if wr.prefix != "" {
+					impl = wr.prefix + strings.ToUpper(impl[:1]) + impl[1:]
+				}
+				ifaces = append(ifaces, o.iface)
+				fields = append(fields, impl)
+				values = append(values, impl+"{w}")
+			}
+			if mask == 0 {
+				fmt.Fprintf(buf, "case 0: // None.\nreturn w\n")
+				continue
+			}
+			fmt.Fprintf(buf, "case %d: // %s.\nreturn struct {\n*%s\n%s\n}{w, %s}\n",
+				mask, strings.Join(ifaces, ", "), wr.typ, strings.Join(fields, "\n"), strings.Join(values, ", "))
+		}
+		fmt.Fprintf(buf, "}\npanic(\"unreachable\")\n}\n")
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("Error formatting the generated code: %s\n%s", err, buf)
+	}
+	if err := os.WriteFile("responsewriter_gen.go", src, 0644); err != nil {
+		log.Fatalf("Error writing the generated code: %s", err)
+	}
+}
@@ -0,0 +1,33 @@
+package ehttp
+
+import (
+	"net/http"
+)
+
+// HeaderError is implemented by the errors carrying headers to send, like *Error (see WithHeader).
+// Some status codes require headers: WWW-Authenticate for 401, Retry-After for 429/503 or Allow for 405.
+type HeaderError interface {
+	error
+	Header() http.Header
+}
+
+// setErrorHeaders sets the headers of the HeaderError found in the error tree.
+// When several errors set the same header, the outermost one wins.
+func setErrorHeaders(h http.Header, err error) {
+	seen := map[string]bool{}
+	walkErrors(err, func(err error) bool {
+		he, ok := err.(HeaderError)
+		if !ok {
+			return true
+		}
+		for k, v := range he.Header() {
+			k = http.CanonicalHeaderKey(k)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			h[k] = append([]string(nil), v...)
+		}
+		return true
+	})
+}
@@ -0,0 +1,46 @@
+package ehttp
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorHeaders(t *testing.T) {
+	e1 := TooManyRequests.WithHeader("Retry-After", "120")
+	if TooManyRequests.Header() != nil {
+		t.Fatal("WithHeader should not alter the original error")
+	}
+	e2 := e1.WithHeader("retry-after", "60").WithHeader("X-Rate-Limit", "10")
+	assertInt(t, 1, len(e1.Header()["Retry-After"]))
+	assertInt(t, 2, len(e2.Header()["Retry-After"]))
+
+	mux := New()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
+		return errors.Join(
+			fmt.Errorf("wrapped: %w", Unauthorized.WithHeader("WWW-Authenticate", `Bearer realm="api"`).WithHeader("X-Rate-Limit", "5")),
+			e2,
+		)
+	})
+	mux.HandleFunc("/late", func(w http.ResponseWriter, req *http.Request) error {
+		w.WriteHeader(http.StatusAccepted)
+		return e1
+	})
+	defer setLogOutput(mux, ioutil.Discard)()
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assertInt(t, http.StatusUnauthorized, rec.Code)
+	assertString(t, `Bearer realm="api"`, rec.Header().Get("WWW-Authenticate"))
+	assertString(t, "5", rec.Header().Get("X-Rate-Limit"))
+	assertInt(t, 2, len(rec.Header()["Retry-After"]))
+
+	// Headers already sent.
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/late", nil))
+	assertInt(t, http.StatusAccepted, rec.Code)
+	assertString(t, "", rec.Result().Header.Get("Retry-After"))
+}
@@ -2,6 +2,9 @@ package ehttp
 
 import (
 	"encoding/json"
+	"io"
+	"log"
+	"log/slog"
 	"path"
 	"runtime"
 	"strings"
@@ -51,3 +54,11 @@ func assertJSONError(t *testing.T, expect, got string) {
 	}
 	t.Errorf("[%s:%d] Unexpected error.\nExpect:\t%s\nGot:\t%s\n", file, line, expect, got)
 }
+
+// setLogOutput redirects the mux logs to the given writer.
+// Returns a func restoring the previous logger.
+func setLogOutput(mux *ServeMux, w io.Writer) func() {
+	logger := mux.log
+	mux.log = slog.New(NewLogHandler(log.New(w, "", log.LstdFlags)))
+	return func() { mux.log = logger }
+}
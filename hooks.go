@@ -0,0 +1,52 @@
+package ehttp
+
+import (
+	"errors"
+	"net/http"
+)
+
+// HookFunc is a callback invoked by the ServeMux error management.
+// Receives the handler's request, the ResponseWriter, the error and the final http status:
+// the one sent for the error or, if the headers were already sent, the one sent by the handler.
+type HookFunc func(w ResponseWriter, req *http.Request, err error, status int)
+
+// Hooks are callbacks invoked when handling errors. Nil hooks are ignored.
+// Hooks are called after the error has been sent to the client.
+type Hooks struct {
+	OnError     HookFunc // Called for every error.
+	OnPanic     HookFunc // Called for every error coming from a recovered panic.
+	OnLateError HookFunc // Called for every error returned after the headers were sent.
+}
+
+// WithHooks registers the given hooks. Can be used multiple times.
+func WithHooks(hooks Hooks) Option {
+	return func(sm *ServeMux) {
+		sm.AddHooks(hooks)
+	}
+}
+
+// AddHooks registers the given hooks.
+// Not safe for concurrent use, hooks are expected to be registered before serving.
+func (sm *ServeMux) AddHooks(hooks Hooks) {
+	sm.hooks = append(sm.hooks, hooks)
+}
+
+// runHooks calls the registered hooks for the given error.
+func (sm *ServeMux) runHooks(w ResponseWriter, req *http.Request, err error, status int, late bool) {
+	if err == nil || len(sm.hooks) == 0 {
+		return
+	}
+	var pe *PanicError
+	isPanic := errors.As(err, &pe)
+	for _, hooks := range sm.hooks {
+		if hooks.OnError != nil {
+			hooks.OnError(w, req, err, status)
+		}
+		if isPanic && hooks.OnPanic != nil {
+			hooks.OnPanic(w, req, err, status)
+		}
+		if late && hooks.OnLateError != nil {
+			hooks.OnLateError(w, req, err, status)
+		}
+	}
+}
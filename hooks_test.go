@@ -0,0 +1,89 @@
+package ehttp
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// hookRecorder records the hook calls.
+type hookRecorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *hookRecorder) hook(name string) HookFunc {
+	return func(w ResponseWriter, req *http.Request, err error, status int) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.calls = append(r.calls, fmt.Sprintf("%s %s %d %s", name, req.URL.Path, status, err))
+	}
+}
+
+func (r *hookRecorder) pop() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := r.calls
+	r.calls = nil
+	return calls
+}
+
+func TestHooks(t *testing.T) {
+	rec := &hookRecorder{}
+	mux := New(
+		WithPanicRecovery(true),
+		WithLogger(log.New(bytes.NewBuffer(nil), "", 0)),
+		WithHooks(Hooks{OnError: rec.hook("error"), OnPanic: rec.hook("panic"), OnLateError: rec.hook("late")}),
+	)
+	mux.AddHooks(Hooks{OnError: rec.hook("error2")})
+	mux.HandleFunc("/error", func(w http.ResponseWriter, req *http.Request) error {
+		return NewErrorf(http.StatusTeapot, "fail")
+	})
+	mux.HandleFunc("/late", func(w http.ResponseWriter, req *http.Request) error {
+		w.WriteHeader(http.StatusAccepted)
+		return NewErrorf(http.StatusTeapot, "fail")
+	})
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, req *http.Request) error {
+		panic(NewErrorf(http.StatusTeapot, "fail"))
+	})
+	mux.HandleFunc("/error-panic", func(w http.ResponseWriter, req *http.Request) (err error) {
+		defer func() { err = mux.HandlePanic(err, recover()) }()
+		err = NewErrorf(http.StatusTeapot, "fail")
+		panic("boom")
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, req *http.Request) error {
+		return nil
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		path   string
+		expect []string
+	}{
+		{path: "/ok"},
+		{path: "/error", expect: []string{"error /error 418 fail", "error2 /error 418 fail"}},
+		{path: "/late", expect: []string{"error /late 202 fail", "late /late 202 fail", "error2 /late 202 fail"}},
+		{path: "/panic", expect: []string{"error /panic 418", "panic /panic 418", "error2 /panic 418"}},
+		{path: "/error-panic", expect: []string{"error /error-panic 418 fail", "panic /error-panic 418 fail", "error2 /error-panic 418 fail"}},
+	} {
+		resp, err := http.Get(ts.URL + tc.path)
+		if err != nil {
+			t.Fatalf("Error requesting test server: %s", err)
+		}
+		_ = resp.Body.Close()
+
+		calls := rec.pop()
+		assertInt(t, len(tc.expect), len(calls))
+		for i := 0; i < len(calls) && i < len(tc.expect); i++ {
+			if got := calls[i]; len(got) < len(tc.expect[i]) || got[:len(tc.expect[i])] != tc.expect[i] {
+				t.Errorf("Unexpected hook call for %s.\nExpect:\t%s\nGot:\t%s", tc.path, tc.expect[i], got)
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package ehttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+// DefaultMaxJSONBodySize is the default maximum size of the request body accepted by DecodeJSON.
+const DefaultMaxJSONBodySize int64 = 1 << 20
+
+// JSONOption configures DecodeJSON and the JSON handlers.
+type JSONOption func(*jsonConfig)
+
+// jsonConfig holds the configuration of DecodeJSON.
+type jsonConfig struct {
+	maxBodySize int64 // Maximum size of the request body.
+}
+
+// WithMaxBodySize sets the maximum size of the request body accepted by DecodeJSON.
+// Defaults to DefaultMaxJSONBodySize.
+func WithMaxBodySize(limit int64) JSONOption {
+	return func(c *jsonConfig) {
+		c.maxBodySize = limit
+	}
+}
+
+// newJSONConfig returns the configuration with the given options applied on top of the defaults.
+func newJSONConfig(opts []JSONOption) jsonConfig {
+	c := jsonConfig{maxBodySize: DefaultMaxJSONBodySize}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// StatusCoder can be implemented by the responses of the JSON handlers
+// to set the http status code. Defaults to http.StatusOK.
+// Also used to get the http status code of the errors, see ValidationError.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// JSON converts the typed function to a HandlerFunc.
+// The request body is decoded in Req with DecodeJSON and the given options, and the response is encoded with WriteJSON.
+// When Req is a pointer type, fn always receives a non-nil value, the zero value if the body is empty.
+//
+//	mux.Post("/users", ehttp.JSON(func(ctx context.Context, req CreateUserRequest) (*User, error) {
+//		return svc.CreateUser(ctx, req)
+//	}, ehttp.WithMaxBodySize(64<<10)))
+func JSON[Req, Resp any](fn func(context.Context, Req) (Resp, error), opts ...JSONOption) HandlerFunc {
+	c := newJSONConfig(opts)
+	return func(w http.ResponseWriter, req *http.Request) error {
+		var in Req
+		if err := c.decodeJSON(w, req, &in); err != nil {
+			return err
+		}
+		// Empty or null body.
+		if v := reflect.ValueOf(&in).Elem(); v.Kind() == reflect.Pointer && v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if err := validate(&in); err != nil {
+			return err
+		}
+		out, err := fn(req.Context(), in)
+		if err != nil {
+			return err
+		}
+		return WriteJSON(w, responseStatus(out), out)
+	}
+}
+
+// responseStatus returns the http status code of the StatusCoder response.
+// Defaults to http.StatusOK when the response is not a StatusCoder, is a nil pointer or returns 0.
+func responseStatus(out interface{}) int {
+	sc, ok := out.(StatusCoder)
+	if !ok {
+		return http.StatusOK
+	}
+	if v := reflect.ValueOf(sc); v.Kind() == reflect.Pointer && v.IsNil() {
+		return http.StatusOK
+	}
+	if status := sc.StatusCode(); status != 0 {
+		return status
+	}
+	return http.StatusOK
+}
+
+// DecodeJSON decodes the request body in v.
+// The unknown fields are rejected and the body is limited to DefaultMaxJSONBodySize, see WithMaxBodySize.
+// An empty body leaves v untouched.
+// Returns a http.StatusBadRequest error if the body is invalid
+// and a http.StatusRequestEntityTooLarge error if the body is too large.
+// Once decoded, v is validated if it implements Validator.
+func DecodeJSON(w http.ResponseWriter, req *http.Request, v interface{}, opts ...JSONOption) error {
+	if err := newJSONConfig(opts).decodeJSON(w, req, v); err != nil {
+		return err
+	}
+	return validate(v)
+}
+
+// decodeJSON decodes the request body in v.
+func (c jsonConfig) decodeJSON(w http.ResponseWriter, req *http.Request, v interface{}) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, req.Body, c.maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return decodeError(err)
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		if err == nil {
+			err = errors.New("body must contain a single JSON value")
+		}
+		return decodeError(err)
+	}
+	return nil
+}
+
+// decodeError converts the given json decode error to an ehttp error.
+func decodeError(err error) error {
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return NewErrorf(http.StatusRequestEntityTooLarge, "request body too large: limit is %d bytes", mbe.Limit)
+	}
+	return NewErrorf(http.StatusBadRequest, "invalid JSON body: %w", err)
+}
+
+// WriteJSON sends v as JSON with the given http status code.
+// Nothing is sent if v can't be encoded, so the error can be handled.
+// The body is omitted for http.StatusNoContent.
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) error {
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return nil
+	}
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, err = w.Write(append(buf, '\n'))
+	return err
+}
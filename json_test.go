@@ -0,0 +1,160 @@
+package ehttp
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type jsonTestRequest struct {
+	Name string `json:"name"`
+}
+
+type jsonTestResponse struct {
+	Greeting string `json:"greeting"`
+	created  bool
+}
+
+func (r jsonTestResponse) StatusCode() int {
+	if r.created {
+		return http.StatusCreated
+	}
+	return http.StatusOK
+}
+
+func TestJSON(t *testing.T) {
+	mux := New()
+	mux.Post("/", JSON(func(ctx context.Context, req jsonTestRequest) (jsonTestResponse, error) {
+		if req.Name == "" {
+			return jsonTestResponse{}, NewErrorf(http.StatusUnprocessableEntity, "missing name")
+		}
+		return jsonTestResponse{Greeting: "hello " + req.Name, created: req.Name == "new"}, nil
+	}, WithMaxBodySize(32)))
+	mux.Get("/", JSON(func(ctx context.Context, req struct{}) ([]string, error) {
+		return []string{"a", "b"}, nil
+	}))
+	mux.Put("/", JSON(func(ctx context.Context, req jsonTestRequest) (map[string]interface{}, error) {
+		return map[string]interface{}{"invalid": func() {}}, nil
+	}))
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		method string
+		body   string
+		status int
+		expect string
+	}{
+		{method: "POST", body: `{"name":"bob"}`, status: http.StatusOK, expect: `{"greeting":"hello bob"}`},
+		{method: "POST", body: `{"name":"new"}`, status: http.StatusCreated, expect: `{"greeting":"hello new"}`},
+		{method: "POST", body: ``, status: http.StatusUnprocessableEntity, expect: `{"errors":["missing name"]}`},
+		{method: "POST", body: `{"name":"bob","age":42}`, status: http.StatusBadRequest, expect: `{"errors":["invalid JSON body: json: unknown field \"age\""]}`},
+		{method: "POST", body: `{"name":`, status: http.StatusBadRequest, expect: `{"errors":["invalid JSON body: unexpected EOF"]}`},
+		{method: "POST", body: `{"name":"bob"}{}`, status: http.StatusBadRequest, expect: `{"errors":["invalid JSON body: body must contain a single JSON value"]}`},
+		{method: "POST", body: `{"name":"` + strings.Repeat("a", 32) + `"}`, status: http.StatusRequestEntityTooLarge, expect: `{"errors":["request body too large: limit is 32 bytes"]}`},
+		{method: "GET", status: http.StatusOK, expect: `["a","b"]`},
+		{method: "PUT", status: http.StatusInternalServerError, expect: `{"errors":["Internal Server Error"]}`},
+	} {
+		req, err := http.NewRequest(tc.method, ts.URL, strings.NewReader(tc.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Error requesting test server: %s", err)
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Error reading response body: %s", err)
+		}
+		assertInt(t, tc.status, resp.StatusCode)
+		assertString(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+		assertString(t, tc.expect+"\n", string(body))
+	}
+}
+
+// jsonTestStatus is a StatusCoder response with a pointer receiver.
+func TestDecodeJSONMaxBodySize(t *testing.T) {
+	body := `{"name":"` + strings.Repeat("a", 64) + `"}`
+
+	var req jsonTestRequest
+	if err := DecodeJSON(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(body)), &req); err != nil {
+		t.Fatalf("Unexpected error with the default limit: %s", err)
+	}
+	err := DecodeJSON(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(body)), &req, WithMaxBodySize(32))
+	assertInt(t, http.StatusRequestEntityTooLarge, StatusOf(err))
+	assertString(t, "request body too large: limit is 32 bytes", PublicMessage(err))
+}
+
+type jsonTestStatus struct {
+	Status int `json:"status"`
+}
+
+func (r *jsonTestStatus) StatusCode() int {
+	return r.Status
+}
+
+func TestJSONStatusDefault(t *testing.T) {
+	mux := New()
+	mux.Get("/zero", JSON(func(ctx context.Context, req struct{}) (*jsonTestStatus, error) {
+		return &jsonTestStatus{}, nil
+	}))
+	mux.Get("/nil", JSON(func(ctx context.Context, req struct{}) (*jsonTestStatus, error) {
+		return nil, nil
+	}))
+	mux.Get("/accepted", JSON(func(ctx context.Context, req struct{}) (*jsonTestStatus, error) {
+		return &jsonTestStatus{Status: http.StatusAccepted}, nil
+	}))
+
+	for _, tc := range []struct {
+		path   string
+		status int
+		expect string
+	}{
+		{path: "/zero", status: http.StatusOK, expect: `{"status":0}`},
+		{path: "/nil", status: http.StatusOK, expect: `null`},
+		{path: "/accepted", status: http.StatusAccepted, expect: `{"status":202}`},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", tc.path, nil))
+		assertInt(t, tc.status, rec.Code)
+		assertString(t, tc.expect+"\n", rec.Body.String())
+	}
+}
+
+func TestWriteJSONNoContent(t *testing.T) {
+	rec := httptest.NewRecorder()
+	if err := WriteJSON(rec, http.StatusNoContent, nil); err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, http.StatusNoContent, rec.Code)
+	assertString(t, "", rec.Body.String())
+	assertString(t, "", rec.Header().Get("Content-Type"))
+}
+
+func ExampleJSON() {
+	type request struct {
+		Name string `json:"name"`
+	}
+	type response struct {
+		Greeting string `json:"greeting"`
+	}
+	mux := New()
+	mux.Post("/hello", JSON(func(ctx context.Context, req request) (*response, error) {
+		if req.Name == "" {
+			return nil, NewErrorf(http.StatusUnprocessableEntity, "missing name")
+		}
+		return &response{Greeting: "hello " + req.Name}, nil
+	}))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/hello", strings.NewReader(`{"name":"world"}`)))
+	fmt.Print(rec.Code, " ", rec.Body.String())
+	// Output: 200 {"greeting":"hello world"}
+}
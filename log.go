@@ -0,0 +1,100 @@
+package ehttp
+
+import (
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"net/http"
+)
+
+// NewLogHandler adapts a standard *log.Logger to a slog.Handler.
+// The records are formatted by slog.TextHandler, without the time
+// as the *log.Logger adds its own.
+func NewLogHandler(logger *log.Logger) slog.Handler {
+	return slog.NewTextHandler(logWriter{logger: logger}, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+}
+
+// logWriter forwards each write to the underlying *log.Logger.
+// slog.TextHandler writes each record in a single call.
+type logWriter struct {
+	logger *log.Logger
+}
+
+// Write implements io.Writer.
+func (w logWriter) Write(buf []byte) (int, error) {
+	return len(buf), w.logger.Output(2, string(buf))
+}
+
+// logger returns the slog.Logger to use. Defaults to slog.Default().
+func (sm *ServeMux) logger() *slog.Logger {
+	if sm.log == nil {
+		return slog.Default()
+	}
+	return sm.log
+}
+
+// logError logs the given message at error level with the request and error attributes.
+func (sm *ServeMux) logError(req *http.Request, msg string, err error, attrs ...slog.Attr) {
+	ctx := context.Background()
+	if req != nil {
+		ctx = req.Context()
+	}
+	attrs = append(requestAttrs(req), attrs...)
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()), slog.Any("error_chain", errorChain(err)))
+	}
+	sm.logger().LogAttrs(ctx, slog.LevelError, msg, attrs...)
+}
+
+// requestAttrs returns the log attributes describing the request.
+func requestAttrs(req *http.Request) []slog.Attr {
+	if req == nil {
+		return nil
+	}
+	attrs := []slog.Attr{slog.String("method", req.Method)}
+	if req.URL != nil {
+		attrs = append(attrs, slog.String("path", req.URL.Path))
+	}
+	if req.Pattern != "" {
+		attrs = append(attrs, slog.String("pattern", req.Pattern))
+	}
+	if id := requestIDFromRequest(req); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	} else if id := req.Header.Get(RequestIDHeader); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	return attrs
+}
+
+// errorChain lists the messages of each error in the error tree, depth-first.
+// Consecutive identical messages (i.e. *Error wrapping its cause) are listed once.
+func errorChain(err error) []string {
+	var chain []string
+	var walk func(error)
+	walk = func(err error) {
+		prev := ""
+		for err != nil {
+			if msg := err.Error(); msg != prev {
+				chain = append(chain, msg)
+				prev = msg
+			}
+			if multi, ok := err.(interface{ Unwrap() []error }); ok {
+				for _, e1 := range multi.Unwrap() {
+					walk(e1)
+				}
+				return
+			}
+			err = errors.Unwrap(err)
+		}
+	}
+	walk(err)
+	return chain
+}
@@ -0,0 +1,121 @@
+package ehttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewLogHandler(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	logger := slog.New(NewLogHandler(log.New(buf, "prefix: ", 0)))
+	logger.Error("hello", "status", http.StatusTeapot)
+	assertString(t, `prefix: level=ERROR msg=hello status=418`, buf.String())
+}
+
+func TestErrorChain(t *testing.T) {
+	err := fmt.Errorf("wrap: %w", errors.Join(NotFound, io.EOF))
+	chain := errorChain(err)
+	assertString(t, "wrap: Not Found\nEOF|Not Found\nEOF|Not Found|EOF", strings.Join(chain, "|"))
+	if errorChain(nil) != nil {
+		t.Fatal("nil error should not have a chain")
+	}
+}
+
+func TestLogHeaderAlreadySent(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	mux := New(WithLogHandler(slog.NewJSONHandler(buf, nil)))
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, req *http.Request) error {
+		fmt.Fprintf(w, "hello")
+		return fmt.Errorf("loading user: %w", NotFound)
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	req, err := http.NewRequest("GET", ts.URL+"/users/abc", nil)
+	if err != nil {
+		t.Fatalf("Error creating request: %s", err)
+	}
+	req.Header.Set("X-Request-Id", "my-request")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Error requesting test server: %s", err)
+	}
+	_ = resp.Body.Close()
+
+	record := struct {
+		Level      string   `json:"level"`
+		Msg        string   `json:"msg"`
+		Method     string   `json:"method"`
+		Path       string   `json:"path"`
+		Pattern    string   `json:"pattern"`
+		RequestID  string   `json:"request_id"`
+		Status     int      `json:"status"`
+		Error      string   `json:"error"`
+		ErrorChain []string `json:"error_chain"`
+	}{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Error parsing log record: %s (%s)", err, buf)
+	}
+	assertString(t, "ERROR", record.Level)
+	assertString(t, "HTTP Error (header already sent)", record.Msg)
+	assertString(t, "GET", record.Method)
+	assertString(t, "/users/abc", record.Path)
+	assertString(t, "GET /users/{id}", record.Pattern)
+	assertString(t, "my-request", record.RequestID)
+	assertInt(t, http.StatusOK, record.Status)
+	assertString(t, "loading user: Not Found", record.Error)
+	assertString(t, "loading user: Not Found|Not Found", strings.Join(record.ErrorChain, "|"))
+}
+
+func TestLogServerError(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	mux := New(WithLogHandler(slog.NewJSONHandler(buf, nil)))
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
+		return errors.New("pq: connection refused")
+	})
+	mux.HandleFunc("/notfound", func(w http.ResponseWriter, req *http.Request) error {
+		return NotFound
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	for _, path := range []string{"/notfound", "/"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("Error requesting test server: %s", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Error reading response body: %s", err)
+		}
+		if path == "/" {
+			assertJSONError(t, "Internal Server Error", string(body))
+		}
+	}
+
+	record := struct {
+		Msg    string `json:"msg"`
+		Path   string `json:"path"`
+		Status int    `json:"status"`
+		Error  string `json:"error"`
+	}{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Error parsing log record: %s (%s)", err, buf)
+	}
+	assertString(t, "HTTP Error", record.Msg)
+	assertString(t, "/", record.Path)
+	assertInt(t, http.StatusInternalServerError, record.Status)
+	assertString(t, "pq: connection refused", record.Error)
+}
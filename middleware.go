@@ -0,0 +1,31 @@
+package ehttp
+
+// Middleware wraps a HandlerFunc. As the error is returned, the middleware
+// can look at, transform or swallow the errors from the inner handlers before they get handled.
+type Middleware func(HandlerFunc) HandlerFunc
+
+// Chain composes the given middlewares into a single one.
+// The first middleware is the outermost one.
+func Chain(mws ...Middleware) Middleware {
+	return func(handler HandlerFunc) HandlerFunc {
+		for i := len(mws) - 1; i >= 0; i-- {
+			handler = mws[i](handler)
+		}
+		return handler
+	}
+}
+
+// Use appends the given middlewares to the ServeMux.
+// The middlewares are applied when the handlers are registered: Use is expected to be called
+// before registering the handlers and is not safe for concurrent use.
+func (sm *ServeMux) Use(mws ...Middleware) {
+	sm.middlewares = append(sm.middlewares, mws...)
+}
+
+// chain applies the ServeMux middlewares to the given handler.
+func (sm *ServeMux) chain(handler HandlerFunc) HandlerFunc {
+	if len(sm.middlewares) == 0 {
+		return handler
+	}
+	return Chain(sm.middlewares...)(handler)
+}
@@ -0,0 +1,98 @@
+package ehttp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// traceMiddleware records its name when called and wraps the returned error.
+func traceMiddleware(name string, trace *[]string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			*trace = append(*trace, name)
+			if err := next(w, req); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			return nil
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	var trace []string
+	hdlr := Chain(traceMiddleware("a", &trace), traceMiddleware("b", &trace))(func(w http.ResponseWriter, req *http.Request) error {
+		trace = append(trace, "handler")
+		return io.EOF
+	})
+	err := hdlr(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assertString(t, "a: b: EOF", err.Error())
+	assertString(t, "a,b,handler", strings.Join(trace, ","))
+
+	if err := Chain()(func(http.ResponseWriter, *http.Request) error { return io.EOF })(nil, nil); err != io.EOF {
+		t.Fatalf("Empty chain should return the handler as is")
+	}
+}
+
+func TestUse(t *testing.T) {
+	var trace []string
+	mux := New(WithPanicRecovery(true), WithErrorEncoder(EncodeText))
+	defer setLogOutput(mux, ioutil.Discard)()
+	mux.Use(traceMiddleware("a", &trace))
+	mux.Use(traceMiddleware("b", &trace), func(next HandlerFunc) HandlerFunc {
+		// Swallow the io.EOF errors and transform not found errors.
+		return func(w http.ResponseWriter, req *http.Request) error {
+			err := next(w, req)
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if errors.Is(err, NotFound) {
+				return NotFound.WithPublic("unknown user")
+			}
+			return err
+		}
+	})
+	mux.HandleFunc("/eof", func(w http.ResponseWriter, req *http.Request) error {
+		return io.EOF
+	})
+	mux.HandleFunc("/notfound", func(w http.ResponseWriter, req *http.Request) error {
+		return NotFound
+	})
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, req *http.Request) error {
+		panic(NewErrorf(http.StatusTeapot, "fail"))
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{path: "/eof", status: http.StatusOK, body: ""},
+		{path: "/notfound", status: http.StatusNotFound, body: "unknown user"},
+		{path: "/panic", status: http.StatusTeapot, body: "fail"},
+	} {
+		trace = nil
+		resp, err := http.Get(ts.URL + tc.path)
+		if err != nil {
+			t.Fatalf("Error requesting test server: %s", err)
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Error reading response body: %s", err)
+		}
+		assertInt(t, tc.status, resp.StatusCode)
+		if !strings.Contains(string(body), tc.body) {
+			t.Errorf("Unexpected body for %s.\nExpect:\t%s\nGot:\t%s", tc.path, tc.body, body)
+		}
+		assertString(t, "a,b", strings.Join(trace, ","))
+	}
+}
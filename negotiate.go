@@ -0,0 +1,115 @@
+package ehttp
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// errorEncoderEntry is an ErrorEncoder registered for content negotiation.
+type errorEncoderEntry struct {
+	contentType string // Content-Type sent with the error.
+	mediaType   string // Media type matched against the Accept header.
+	encoder     ErrorEncoder
+}
+
+// WithEncoder registers the given ErrorEncoder for content negotiation:
+// the encoder is used when the request's Accept header prefers the media type of the given Content-Type.
+// Can be used multiple times. On equal preference, the first registered encoder is used.
+// When the Accept header is missing, doesn't match any registered encoder or only matches via "*/*",
+// the default encoder and Content-Type are used (see WithErrorEncoder and WithContentType).
+func WithEncoder(contentType string, encoder ErrorEncoder) Option {
+	return func(sm *ServeMux) {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || encoder == nil {
+			return
+		}
+		sm.encoders = append(sm.encoders, errorEncoderEntry{
+			contentType: contentType,
+			mediaType:   mediaType,
+			encoder:     encoder,
+		})
+	}
+}
+
+// WithNegotiation registers the built-in encoders for content negotiation:
+// text/html (the minimal error page of HTMLEncoder, for the browsers), application/json,
+// application/problem+json, application/xml, text/plain and text/xml.
+// See WithEncoder. To use a custom error page, register its HTMLEncoder before WithNegotiation.
+func WithNegotiation() Option {
+	return func(sm *ServeMux) {
+		for _, opt := range []Option{
+			WithEncoder("text/html; charset=utf-8", HTMLEncoder(nil)),
+			WithEncoder("application/json; charset=utf-8", EncodeJSON),
+			WithEncoder(ProblemContentType, EncodeProblem),
+			WithEncoder("application/xml; charset=utf-8", EncodeXML),
+			WithEncoder("text/plain; charset=utf-8", EncodeText),
+			WithEncoder("text/xml; charset=utf-8", EncodeXML),
+		} {
+			opt(sm)
+		}
+	}
+}
+
+// negotiate selects the ErrorEncoder and Content-Type to use based on the request's Accept header.
+func (sm *ServeMux) negotiate(req *http.Request) (ErrorEncoder, string) {
+	if len(sm.encoders) == 0 || req == nil {
+		return sm.sendError, sm.errorContentType
+	}
+	accept := parseAccept(req.Header.Get("Accept"))
+	best, bestQ := -1, 0.
+	for i, entry := range sm.encoders {
+		if q := accept.quality(entry.mediaType); q > bestQ {
+			best, bestQ = i, q
+		}
+	}
+	if best == -1 {
+		return sm.sendError, sm.errorContentType
+	}
+	return sm.encoders[best].encoder, sm.encoders[best].contentType
+}
+
+// acceptRange is a media range from the Accept header.
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// acceptRanges is a parsed Accept header.
+type acceptRanges []acceptRange
+
+// parseAccept parses the Accept header. Invalid media ranges are ignored.
+func parseAccept(header string) acceptRanges {
+	var ranges acceptRanges
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.
+		if qStr, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(qStr, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+	return ranges
+}
+
+// quality returns the quality of the given media type using the most specific matching range.
+// The "*/*" range is not considered, so the default encoder is used when the client accepts anything.
+func (ranges acceptRanges) quality(mediaType string) float64 {
+	typ, _, _ := strings.Cut(mediaType, "/")
+	q := 0.
+	for _, r := range ranges {
+		if r.mediaType == mediaType {
+			return r.q
+		}
+		if r.mediaType == typ+"/*" {
+			q = r.q
+		}
+	}
+	return q
+}
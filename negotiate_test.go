@@ -0,0 +1,100 @@
+package ehttp
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAcceptQuality(t *testing.T) {
+	accept := parseAccept("text/html, application/xhtml+xml, application/xml;q=0.9, text/*;q=0.5, invalid/, */*;q=0.8")
+	assertInt(t, 5, len(accept))
+	for _, tc := range []struct {
+		mediaType string
+		expect    float64
+	}{
+		{mediaType: "text/html", expect: 1},
+		{mediaType: "application/xml", expect: 0.9},
+		{mediaType: "text/plain", expect: 0.5},
+		{mediaType: "application/json", expect: 0},
+	} {
+		if got := accept.quality(tc.mediaType); got != tc.expect {
+			t.Errorf("Unexpected quality for %s.\nExpect:\t%v\nGot:\t%v", tc.mediaType, tc.expect, got)
+		}
+	}
+	if parseAccept("text/html;q=abc") != nil {
+		t.Error("Invalid quality should be ignored")
+	}
+}
+
+func TestNegotiation(t *testing.T) {
+	mux := New(WithNegotiation(), WithEncoder("invalid/", EncodeText))
+	assertInt(t, 6, len(mux.encoders))
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
+		return NewErrorf(http.StatusTeapot, "fail")
+	})
+
+	for _, tc := range []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{accept: "", contentType: "application/json; charset=utf-8", body: `{"errors":["fail"]}`},
+		{accept: "*/*", contentType: "application/json; charset=utf-8", body: `{"errors":["fail"]}`},
+		{accept: "image/png", contentType: "application/json; charset=utf-8", body: `{"errors":["fail"]}`},
+		{accept: "application/json", contentType: "application/json; charset=utf-8", body: `{"errors":["fail"]}`},
+		{accept: "application/problem+json, application/json;q=0.9", contentType: ProblemContentType, body: `"detail":"fail"`},
+		{accept: "text/plain;q=0.5, application/xml", contentType: "application/xml; charset=utf-8", body: `<errors><error>fail</error></errors>`},
+		{accept: "text/xml", contentType: "text/xml; charset=utf-8", body: `<errors><error>fail</error></errors>`},
+		{accept: "text/plain", contentType: "text/plain; charset=utf-8", body: "fail"},
+		{accept: "text/*", contentType: "text/html; charset=utf-8", body: "<h1>418 I&#39;m a teapot</h1>"},
+		// Firefox and Chrome.
+		{accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", contentType: "text/html; charset=utf-8", body: "<h1>418 I&#39;m a teapot</h1>"},
+		{accept: "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7", contentType: "text/html; charset=utf-8", body: "<p>fail</p>"},
+	} {
+		req := httptest.NewRequest("GET", "/", nil)
+		if tc.accept != "" {
+			req.Header.Set("Accept", tc.accept)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		assertInt(t, http.StatusTeapot, rec.Code)
+		assertString(t, tc.contentType, rec.Header().Get("Content-Type"))
+		assertString(t, "Accept", rec.Header().Get("Vary"))
+		if !strings.Contains(rec.Body.String(), tc.body) {
+			t.Errorf("Unexpected body for Accept: %q.\nExpect:\t%s\nGot:\t%s", tc.accept, tc.body, rec.Body.String())
+		}
+	}
+}
+
+func TestNoNegotiation(t *testing.T) {
+	mux := New()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
+		return NewErrorf(http.StatusTeapot, "fail")
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "text/plain")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	assertString(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	assertString(t, "", rec.Header().Get("Vary"))
+	assertJSONError(t, "fail", rec.Body.String())
+}
+
+func TestNegotiationCustomErrorPage(t *testing.T) {
+	tmpl := template.Must(template.New("error").Parse("custom {{.Status}}: {{.Message}}"))
+	mux := New(WithEncoder("text/html; charset=utf-8", HTMLEncoder(tmpl)), WithNegotiation())
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
+		return NewErrorf(http.StatusTeapot, "fail")
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	assertString(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	assertString(t, "custom 418: fail", rec.Body.String())
+}
@@ -0,0 +1,102 @@
+package ehttp
+
+import (
+	"log"
+	"log/slog"
+	"net/http"
+)
+
+// Option configures a ServeMux. See New.
+type Option func(*ServeMux)
+
+// WithErrorEncoder sets the callback used to send the errors to the client.
+// Ignored if nil.
+func WithErrorEncoder(encoder ErrorEncoder) Option {
+	return func(sm *ServeMux) {
+		if encoder != nil {
+			sm.sendError = encoder
+		}
+	}
+}
+
+// WithContentType sets the Content-Type to use for the error cases.
+// An empty value disables it.
+func WithContentType(contentType string) Option {
+	return func(sm *ServeMux) {
+		sm.errorContentType = contentType
+	}
+}
+
+// WithProblemDetails sends the errors as RFC 9457 problem details.
+// Shortcut for WithErrorEncoder(EncodeProblem) and WithContentType(ProblemContentType).
+func WithProblemDetails() Option {
+	return func(sm *ServeMux) {
+		sm.sendError = EncodeProblem
+		sm.errorContentType = ProblemContentType
+	}
+}
+
+// WithPanicRecovery sets whether or not to recover from panics.
+func WithPanicRecovery(recoverPanic bool) Option {
+	return func(sm *ServeMux) {
+		sm.recoverPanic = recoverPanic
+	}
+}
+
+// WithLogger sets the logger used for the errors which can't be sent to the client.
+// The structured records are adapted via NewLogHandler.
+// Ignored if nil.
+func WithLogger(logger *log.Logger) Option {
+	return func(sm *ServeMux) {
+		if logger != nil {
+			sm.log = slog.New(NewLogHandler(logger))
+		}
+	}
+}
+
+// WithSlogLogger sets the structured logger used for the errors which can't be sent to the client.
+// Ignored if nil.
+func WithSlogLogger(logger *slog.Logger) Option {
+	return func(sm *ServeMux) {
+		if logger != nil {
+			sm.log = logger
+		}
+	}
+}
+
+// WithLogHandler sets the slog.Handler used for the errors which can't be sent to the client.
+// Ignored if nil.
+func WithLogHandler(handler slog.Handler) Option {
+	return func(sm *ServeMux) {
+		if handler != nil {
+			sm.log = slog.New(handler)
+		}
+	}
+}
+
+// WithServeMux sets the underlying *net/http.ServeMux.
+// Ignored if nil.
+func WithServeMux(mux *http.ServeMux) Option {
+	return func(sm *ServeMux) {
+		if mux != nil {
+			sm.ServeMux = mux
+		}
+	}
+}
+
+// WithDebug sets whether or not to send the stack traces of the recovered panics to the client.
+// Not to be used in production.
+func WithDebug(debug bool) Option {
+	return func(sm *ServeMux) {
+		sm.debug = debug
+	}
+}
+
+// WithBuffering buffers the responses up to limit bytes so errors returned
+// before reaching the limit can still be sent as proper error responses.
+// Disabled if 0. See Buffered.
+func WithBuffering(limit int) Option {
+	return func(sm *ServeMux) {
+		sm.bufferLimit = limit
+	}
+}
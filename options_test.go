@@ -0,0 +1,144 @@
+package ehttp
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewDefaults(t *testing.T) {
+	mux := New()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
+		return NewErrorf(http.StatusTeapot, "fail")
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("Error requesting test server: %s", err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Error reading response body: %s", err)
+	}
+	assertInt(t, http.StatusTeapot, resp.StatusCode)
+	assertString(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+	assertJSONError(t, "fail", string(body))
+	if mux.ServeMux == http.DefaultServeMux {
+		t.Fatal("New should not use the net/http.DefaultServeMux")
+	}
+}
+
+func TestNewOptions(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	stdMux := http.NewServeMux()
+	mux := New(
+		WithErrorEncoder(func(w ResponseWriter, req *http.Request, err error) {
+			fmt.Fprintf(w, "custom: %s", err)
+		}),
+		WithContentType("text/plain"),
+		WithPanicRecovery(true),
+		WithLogger(log.New(buf, "", 0)),
+		WithServeMux(stdMux),
+	)
+	if mux.ServeMux != stdMux {
+		t.Fatal("WithServeMux should set the underlying mux")
+	}
+	if !mux.RecoverPanic() {
+		t.Fatal("WithPanicRecovery should enable panic recovery")
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
+		fmt.Fprintf(w, "hello")
+		panic(fmt.Errorf("fail"))
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("Error requesting test server: %s", err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Error reading response body: %s", err)
+	}
+	assertInt(t, http.StatusOK, resp.StatusCode)
+	assertString(t, "hello", string(body))
+	if !strings.Contains(buf.String(), "fail") {
+		t.Errorf("Error not found in custom logger output.\nGot: %s", buf.String())
+	}
+}
+
+func TestNewNilOptions(t *testing.T) {
+	mux := New(WithErrorEncoder(nil), WithLogger(nil), WithSlogLogger(nil), WithLogHandler(nil), WithServeMux(nil))
+	if mux.sendError == nil || mux.log != nil || mux.ServeMux == nil {
+		t.Fatal("nil options should be ignored")
+	}
+}
+
+func TestWithProblemDetails(t *testing.T) {
+	mux := New(WithProblemDetails())
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
+		return BadRequest
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("Error requesting test server: %s", err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Error reading response body: %s", err)
+	}
+	assertInt(t, http.StatusBadRequest, resp.StatusCode)
+	assertString(t, ProblemContentType, resp.Header.Get("Content-Type"))
+	assertString(t, `{"title":"Bad Request","status":400,"detail":"Bad Request","instance":"/"}`, string(body))
+}
+
+func TestWith(t *testing.T) {
+	mux := New(WithErrorEncoder(EncodeText), WithContentType("text/plain"))
+	mux.Use(func(next HandlerFunc) HandlerFunc { return next })
+	admin := mux.With(WithProblemDetails())
+	admin.Use(func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) error {
+			return NewErrorf(http.StatusForbidden, "forbidden")
+		}
+	})
+	assertInt(t, 1, len(mux.middlewares))
+	assertInt(t, 2, len(admin.middlewares))
+
+	mux.HandleFunc("/public", func(w http.ResponseWriter, req *http.Request) error {
+		return NewErrorf(http.StatusTeapot, "fail")
+	})
+	admin.HandleFunc("/admin", func(w http.ResponseWriter, req *http.Request) error {
+		return nil
+	})
+
+	// Both handlers are served by both muxes.
+	for _, sm := range []*ServeMux{mux, admin} {
+		rec := httptest.NewRecorder()
+		sm.ServeHTTP(rec, httptest.NewRequest("GET", "/public", nil))
+		assertInt(t, http.StatusTeapot, rec.Code)
+		assertString(t, "text/plain", rec.Header().Get("Content-Type"))
+		assertString(t, "fail\n", rec.Body.String())
+
+		rec = httptest.NewRecorder()
+		sm.ServeHTTP(rec, httptest.NewRequest("GET", "/admin", nil))
+		assertInt(t, http.StatusForbidden, rec.Code)
+		assertString(t, ProblemContentType, rec.Header().Get("Content-Type"))
+	}
+}
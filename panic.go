@@ -0,0 +1,149 @@
+package ehttp
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+)
+
+// PanicError is the error created from a recovered panic.
+// It keeps the stack of the panicking goroutine.
+// The stack is logged server side but never sent to the client unless in debug mode, see WithDebug.
+type PanicError struct {
+	Value interface{} // Recovered value.
+	error             // Recovered value as an error.
+	pcs   []uintptr   // Program counters of the stack.
+	skip  int         // Number of frames to skip to start from the function which panicked.
+}
+
+// newPanicError creates a new PanicError from the recovered value.
+// Expected to be called from a deferred function while panicking
+// so the stack of the panicking goroutine can be captured.
+func newPanicError(e1 interface{}) *PanicError {
+	err, ok := e1.(error)
+	if !ok { // the panic is not an error, create an error out of the string representation of the panic.
+		err = fmt.Errorf("(%T) %v", e1, e1)
+	}
+	pcs, skip := panicCallers()
+	return &PanicError{
+		Value: e1,
+		error: err,
+		pcs:   pcs,
+		skip:  skip,
+	}
+}
+
+// panicCallers returns the program counters of the current goroutine and
+// the number of frames to skip to start from the function which panicked.
+// Returns nil when not called while panicking.
+func panicCallers() ([]uintptr, int) {
+	pcs := make([]uintptr, 64)
+	for {
+		n := runtime.Callers(1, pcs)
+		if n < len(pcs) {
+			pcs = pcs[:n]
+			break
+		}
+		pcs = make([]uintptr, 2*len(pcs))
+	}
+
+	frames := runtime.CallersFrames(pcs)
+	panicking := false
+	for skip := 0; ; skip++ {
+		frame, more := frames.Next()
+		// Skip the recover machinery until runtime.gopanic, then
+		// the runtime frames of runtime panics (nil dereference or other).
+		if frame.Function == "runtime.gopanic" {
+			panicking = true
+		} else if panicking && !strings.HasPrefix(frame.Function, "runtime.") {
+			return pcs, skip
+		}
+		if !more {
+			return nil, 0
+		}
+	}
+}
+
+// Error implements the error interface.
+// Prefixes the error message with the function and location which panicked.
+func (e *PanicError) Error() string {
+	frames := e.Frames()
+	if len(frames) == 0 {
+		return e.error.Error()
+	}
+	return fmt.Sprintf("[%s %s:%d] %s", path.Base(frames[0].Function), path.Base(frames[0].File), frames[0].Line, e.error)
+}
+
+// Unwrap exposes the recovered value as an error.
+func (e *PanicError) Unwrap() error {
+	return e.error
+}
+
+// Frames returns the stack frames of the panicking goroutine, starting with the function which panicked.
+func (e *PanicError) Frames() []runtime.Frame {
+	if len(e.pcs) == 0 {
+		return nil
+	}
+	var ret []runtime.Frame
+	frames := runtime.CallersFrames(e.pcs)
+	for i := 0; ; i++ {
+		frame, more := frames.Next()
+		if i >= e.skip {
+			ret = append(ret, frame)
+		}
+		if !more {
+			return ret
+		}
+	}
+}
+
+// Stack returns the formatted stack of the panicking goroutine.
+func (e *PanicError) Stack() string {
+	buf := &strings.Builder{}
+	for _, frame := range e.Frames() {
+		fmt.Fprintf(buf, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+	}
+	return buf.String()
+}
+
+// handlerPanicError is the error set by the handler before panicking.
+// The handler error is sent to the client and the panic is exposed to errors.As.
+type handlerPanicError struct {
+	error             // Error set by the handler.
+	panic *PanicError // Recovered panic.
+}
+
+// Unwrap exposes the error set by the handler.
+func (e *handlerPanicError) Unwrap() error {
+	return e.error
+}
+
+// As exposes the recovered panic to errors.As.
+func (e *handlerPanicError) As(target interface{}) bool {
+	pe, ok := target.(**PanicError)
+	if ok {
+		*pe = e.panic
+	}
+	return ok
+}
+
+// debugError exposes the stack of the recovered panics to the client. See WithDebug.
+type debugError struct {
+	error
+}
+
+// Error implements the error interface. Appends the panic stack, if any.
+func (e debugError) Error() string {
+	var pe *PanicError
+	if !errors.As(e.error, &pe) {
+		return e.error.Error()
+	}
+	return e.error.Error() + "\n\n" + pe.Stack()
+}
+
+// Unwrap exposes the underlying error.
+func (e debugError) Unwrap() error {
+	return e.error
+}
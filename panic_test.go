@@ -0,0 +1,87 @@
+package ehttp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPanicError(t *testing.T) {
+	var (
+		file string
+		name string
+		line int
+		pe   *PanicError
+	)
+	func() {
+		defer func() { pe = newPanicError(recover()) }()
+		name, file, line = getCallstack(0)
+		panic(io.EOF)
+	}()
+
+	assertString(t, fmt.Sprintf("[%s %s:%d] EOF", name, file, line+1), pe.Error())
+	if pe.Value != io.EOF || !errors.Is(pe, io.EOF) {
+		t.Fatalf("PanicError should expose the recovered value")
+	}
+	frames := pe.Frames()
+	if len(frames) < 2 {
+		t.Fatalf("Unexpected stack frames: %v", frames)
+	}
+	assertString(t, "github.com/creack/ehttp.TestPanicError.func1", frames[0].Function)
+	assertString(t, "github.com/creack/ehttp.TestPanicError", frames[1].Function)
+	if !strings.HasPrefix(pe.Stack(), "github.com/creack/ehttp.TestPanicError.func1\n\t") {
+		t.Fatalf("Unexpected stack: %s", pe.Stack())
+	}
+}
+
+func TestPanicErrorPublicMessage(t *testing.T) {
+	var pe *PanicError
+	func() {
+		defer func() { pe = newPanicError(recover()) }()
+		panic(NewErrorf(http.StatusTeapot, "fail"))
+	}()
+
+	// The panic location is part of the stack, not sent to the client unless in debug mode.
+	assertString(t, "fail", PublicMessage(pe))
+	assertString(t, "fail", PublicMessage(fmt.Errorf("wrap: %w", pe)))
+	assertString(t, pe.Error(), strings.SplitN(PublicMessage(debugError{pe}), "\n", 2)[0])
+}
+
+func TestPanicErrorNotPanicking(t *testing.T) {
+	pe := newPanicError("fail")
+	assertString(t, "(string) fail", pe.Error())
+	assertInt(t, 0, len(pe.Frames()))
+	assertString(t, "", pe.Stack())
+}
+
+func TestPanicErrorDebug(t *testing.T) {
+	for _, debug := range []bool{false, true} {
+		mux := New(WithPanicRecovery(true), WithDebug(debug), WithErrorEncoder(EncodeText))
+		defer setLogOutput(mux, ioutil.Discard)()
+		mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
+			panic(NewErrorf(http.StatusTeapot, "fail"))
+		})
+
+		ts := httptest.NewServer(mux)
+		defer ts.Close()
+
+		resp, err := http.Get(ts.URL)
+		if err != nil {
+			t.Fatalf("Error requesting test server: %s", err)
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Error reading response body: %s", err)
+		}
+		assertInt(t, http.StatusTeapot, resp.StatusCode)
+		if expect, got := debug, strings.Contains(string(body), "\n\ngithub.com/creack/ehttp.TestPanicErrorDebug.func1\n\t"); expect != got {
+			t.Errorf("Unexpected stack trace in response (debug: %t).\nGot: %s", debug, body)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package ehttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ProblemContentType is the Content-Type of the RFC 9457 (formerly RFC 7807) problem details responses.
+const ProblemContentType = "application/problem+json"
+
+// Problem is the RFC 9457 problem details object sent to the client by EncodeProblem.
+type Problem struct {
+	Type       string                 `json:"type,omitempty"`
+	Title      string                 `json:"title,omitempty"`
+	Status     int                    `json:"status,omitempty"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Extensions map[string]interface{} `json:"-"` // Extension members, serialized alongside the standard ones.
+}
+
+// MarshalJSON implements json.Marshaler. Inlines the extension members.
+// Extension members can't override the standard ones.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem // Alias type to avoid recursion.
+	buf, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return buf, err
+	}
+	m := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		m[k] = v
+	}
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+// NewProblem creates the problem details for the given error and http status.
+// The problem type, title, instance and extensions are taken from the first
+// *Error found in the error chain. When the type is not set, it is
+// "about:blank" and the title defaults to the http status text.
+// The ValidationError fields are sent as the "fields" extension member
+// the messages of the multi errors as the "errors" extension member
+// the application error code as the "code" extension member
+// and the request ID as the "request_id" extension member.
+func NewProblem(req *http.Request, status int, err error) *Problem {
+	p := &Problem{
+		Status: status,
+		Detail: PublicMessage(err),
+	}
+	var e1 *Error
+	if errors.As(err, &e1) {
+		p.Type = e1.Type()
+		p.Title = e1.Title()
+		p.Instance = e1.Instance()
+		p.Extensions = e1.Extensions()
+	}
+	if fields := fieldErrors(err); fields != nil {
+		p.setExtension("fields", fields)
+	}
+	if code := CodeOf(err); code != "" {
+		p.setExtension("code", code)
+	}
+	if id := requestIDFromRequest(req); id != "" {
+		p.setExtension("request_id", id)
+	}
+	if msgs := PublicMessages(err); len(msgs) > 1 {
+		p.setExtension("errors", msgs)
+	}
+	if p.Type == "" && p.Title == "" {
+		p.Title = http.StatusText(status)
+	}
+	if p.Instance == "" && req != nil && req.URL != nil {
+		p.Instance = req.URL.Path
+	}
+	return p
+}
+
+// setExtension sets the extension member on a copy of the extensions,
+// so the extensions of the error are not altered.
+func (p *Problem) setExtension(key string, value interface{}) {
+	ext := make(map[string]interface{}, len(p.Extensions)+1)
+	for k, v := range p.Extensions {
+		ext[k] = v
+	}
+	ext[key] = value
+	p.Extensions = ext
+}
+
+// EncodeProblem is an ErrorEncoder sending errors as RFC 9457 problem details.
+// To be used with ProblemContentType as error Content-Type, see WithProblemDetails:
+//
+//	mux := ehttp.New(ehttp.WithProblemDetails())
+func EncodeProblem(w ResponseWriter, req *http.Request, err error) {
+	status := w.Code()
+	if status == 0 {
+		status = statusCode(err)
+	}
+	_ = json.NewEncoder(w).Encode(NewProblem(req, status, err))
+}
@@ -0,0 +1,97 @@
+package ehttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProblemMarshalJSON(t *testing.T) {
+	p := Problem{
+		Title:      "fail",
+		Status:     http.StatusTeapot,
+		Extensions: map[string]interface{}{"balance": 30, "title": "override"},
+	}
+	buf, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertString(t, `{"balance":30,"status":418,"title":"fail"}`, string(buf))
+
+	buf, err = json.Marshal(Problem{Status: http.StatusTeapot})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertString(t, `{"status":418}`, string(buf))
+}
+
+func TestNewProblem(t *testing.T) {
+	req := httptest.NewRequest("GET", "/account/12", nil)
+
+	p := NewProblem(req, http.StatusInternalServerError, io.EOF)
+	assertString(t, "", p.Type)
+	assertString(t, "Internal Server Error", p.Title)
+	assertString(t, "Internal Server Error", p.Detail)
+	assertString(t, "/account/12", p.Instance)
+
+	e1 := NewErrorf(http.StatusForbidden, "balance too low").(*Error).
+		WithType("https://example.com/probs/out-of-credit").
+		WithTitle("You do not have enough credit.").
+		WithInstance("/account/12/msgs/abc").
+		WithExtension("balance", 30)
+	p = NewProblem(req, http.StatusForbidden, fmt.Errorf("wrap: %w", e1))
+	assertString(t, "https://example.com/probs/out-of-credit", p.Type)
+	assertString(t, "You do not have enough credit.", p.Title)
+	assertString(t, "balance too low", p.Detail)
+	assertString(t, "/account/12/msgs/abc", p.Instance)
+	assertInt(t, 30, p.Extensions["balance"].(int))
+}
+
+func TestErrorWithDoesNotAlterOriginal(t *testing.T) {
+	e1 := NotFound.WithType("https://example.com/probs/not-found").WithExtension("id", 1)
+	e2 := e1.WithExtension("id", 2)
+
+	assertString(t, "", NotFound.Type())
+	if NotFound.Extensions() != nil {
+		t.Fatalf("common error should not be altered")
+	}
+	assertInt(t, 1, e1.Extensions()["id"].(int))
+	assertInt(t, 2, e2.Extensions()["id"].(int))
+	assertInt(t, http.StatusNotFound, e2.Code())
+}
+
+func TestEncodeProblem(t *testing.T) {
+	mux := NewServeMux(EncodeProblem, ProblemContentType, false, nil)
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
+		return NotFound.WithType("https://example.com/probs/not-found").WithExtension("id", "abc")
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/users/abc")
+	if err != nil {
+		t.Fatalf("Error requesting test server: %s", err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Error reading response body: %s", err)
+	}
+	assertInt(t, http.StatusNotFound, resp.StatusCode)
+	assertString(t, ProblemContentType, resp.Header.Get("Content-Type"))
+
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(body, &m); err != nil {
+		t.Fatalf("Error parsing problem details: %s (%s)", err, body)
+	}
+	assertString(t, "https://example.com/probs/not-found", m["type"].(string))
+	assertInt(t, http.StatusNotFound, int(m["status"].(float64)))
+	assertString(t, "Not Found", m["detail"].(string))
+	assertString(t, "/users/abc", m["instance"].(string))
+	assertString(t, "abc", m["id"].(string))
+}
@@ -0,0 +1,78 @@
+package ehttp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader is the header used to receive and send the request ID. See WithRequestID.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen is the maximum length of the incoming request IDs.
+const maxRequestIDLen = 128
+
+// requestIDKey is the context key of the request ID.
+type requestIDKey struct{}
+
+// WithRequestID enables the request IDs: the incoming X-Request-ID header is used if valid,
+// otherwise a new ID is generated. The ID is stored in the request context (see RequestIDFrom),
+// sent back in the X-Request-ID header and included in the error responses of the built-in encoders,
+// the logs and, through the request, the hooks.
+func WithRequestID() Option {
+	return func(sm *ServeMux) {
+		sm.requestID = true
+	}
+}
+
+// RequestIDFrom returns the request ID stored in the context, if any.
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestIDFromRequest returns the request ID stored in the request context, if any.
+func requestIDFromRequest(req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+	return RequestIDFrom(req.Context())
+}
+
+// withRequestID stores the request ID in the request context and sends it back to the client,
+// if enabled and not already done.
+func (sm *ServeMux) withRequestID(w http.ResponseWriter, req *http.Request) *http.Request {
+	if !sm.requestID || req == nil || RequestIDFrom(req.Context()) != "" {
+		return req
+	}
+	id := req.Header.Get(RequestIDHeader)
+	if !validRequestID(id) {
+		id = newRequestID()
+	}
+	w.Header().Set(RequestIDHeader, id)
+	return req.WithContext(context.WithValue(req.Context(), requestIDKey{}, id))
+}
+
+// validRequestID makes sure the incoming request ID is safe to send back and log.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID generates a new random request ID.
+func newRequestID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
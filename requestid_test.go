@@ -0,0 +1,102 @@
+package ehttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	var hookIDs []string
+	buf := bytes.NewBuffer(nil)
+	mux := New(WithRequestID(), WithPanicRecovery(true), WithHooks(Hooks{
+		OnError: func(w ResponseWriter, req *http.Request, err error, status int) {
+			hookIDs = append(hookIDs, RequestIDFrom(req.Context()))
+		},
+	}))
+	defer setLogOutput(mux, buf)()
+	var handlerID string
+	mux.Get("/ok", func(w http.ResponseWriter, req *http.Request) error {
+		handlerID = RequestIDFrom(req.Context())
+		return nil
+	})
+	mux.Get("/fail", func(w http.ResponseWriter, req *http.Request) error {
+		panic("fail")
+	})
+
+	for _, tc := range []struct {
+		path     string
+		incoming string
+		status   int
+		expectID string // Empty to expect a generated ID.
+	}{
+		{path: "/ok", incoming: "abc-123", status: http.StatusOK, expectID: "abc-123"},
+		{path: "/ok", status: http.StatusOK},
+		{path: "/fail", incoming: "abc-456", status: http.StatusInternalServerError, expectID: "abc-456"},
+		{path: "/fail", incoming: "invalid\tid", status: http.StatusInternalServerError},
+		{path: "/fail", incoming: strings.Repeat("a", 129), status: http.StatusInternalServerError},
+		{path: "/unknown", incoming: "abc-789", status: http.StatusNotFound, expectID: "abc-789"},
+	} {
+		hookIDs, handlerID = nil, ""
+		buf.Reset()
+
+		req := httptest.NewRequest("GET", tc.path, nil)
+		if tc.incoming != "" {
+			req.Header.Set(RequestIDHeader, tc.incoming)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		assertInt(t, tc.status, rec.Code)
+
+		id := rec.Header().Get(RequestIDHeader)
+		if tc.expectID != "" {
+			assertString(t, tc.expectID, id)
+		} else if len(id) != 32 || id == tc.incoming {
+			t.Errorf("Unexpected generated request ID: %q", id)
+		}
+		if tc.status == http.StatusOK {
+			assertString(t, id, handlerID)
+			continue
+		}
+		jsonErr := &JSONError{}
+		if err := json.NewDecoder(rec.Body).Decode(jsonErr); err != nil {
+			t.Fatalf("Error parsing error output: %s", err)
+		}
+		assertString(t, id, jsonErr.RequestID)
+		assertInt(t, 1, len(hookIDs))
+		assertString(t, id, hookIDs[0])
+		if tc.path == "/fail" && !strings.Contains(buf.String(), "request_id="+id) {
+			t.Errorf("Request ID not found in log output: %s", buf)
+		}
+	}
+}
+
+func TestRequestIDEncoders(t *testing.T) {
+	for _, tc := range []struct {
+		encoder ErrorEncoder
+		expect  string
+	}{
+		{encoder: EncodeText, expect: "Not Found\nrequest id: abc\n"},
+		{encoder: EncodeXML, expect: `<errors request_id="abc"><error>Not Found</error></errors>`},
+		{encoder: EncodeProblem, expect: `"request_id":"abc"`},
+		{encoder: HTMLEncoder(nil), expect: "<p>Request ID: <code>abc</code></p>"},
+	} {
+		mux := New(WithRequestID(), WithErrorEncoder(tc.encoder))
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(RequestIDHeader, "abc")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if !strings.Contains(rec.Body.String(), tc.expect) {
+			t.Errorf("Unexpected body.\nExpect:\t%s\nGot:\t%s", tc.expect, rec.Body)
+		}
+	}
+
+	// Disabled by default.
+	rec := httptest.NewRecorder()
+	New().ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assertString(t, "", rec.Header().Get(RequestIDHeader))
+	assertString(t, `{"errors":["Not Found"]}`+"\n", rec.Body.String())
+}
@@ -0,0 +1,171 @@
+package ehttp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Enforce that the ehttp writers can be unwrapped by http.ResponseController.
+var (
+	_ rwUnwrapper = (*responseWriter)(nil)
+	_ rwUnwrapper = (*bufferedResponseWriter)(nil)
+	_ rwUnwrapper = (*statusInterceptor)(nil)
+)
+
+type rwUnwrapper interface {
+	Unwrap() http.ResponseWriter
+}
+
+// testWrapper is a third party wrapper only exposing Unwrap.
+type testWrapper struct {
+	http.ResponseWriter
+}
+
+func (w testWrapper) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// responseControllerWrappers lists the ways an ehttp handler can see the writer.
+var responseControllerWrappers = []struct {
+	name string
+	wrap func(http.ResponseWriter) http.ResponseWriter
+}{
+	{"single", func(w http.ResponseWriter) http.ResponseWriter {
+		return NewResponseWriter(w)
+	}},
+	{"buffered", func(w http.ResponseWriter) http.ResponseWriter {
+		return wrapBufferedResponseWriter(newBufferedResponseWriter(NewResponseWriter(w), 1024))
+	}},
+	{"interceptor", func(w http.ResponseWriter) http.ResponseWriter {
+		return &statusInterceptor{ResponseWriter: NewResponseWriter(w)}
+	}},
+	{"nested", func(w http.ResponseWriter) http.ResponseWriter {
+		return NewResponseWriter(testWrapper{wrapBufferedResponseWriter(newBufferedResponseWriter(NewResponseWriter(testWrapper{w}), 1024))})
+	}},
+}
+
+func TestResponseController(t *testing.T) {
+	for _, tc := range responseControllerWrappers {
+		t.Run(tc.name, func(t *testing.T) {
+			errCh := make(chan error, 1)
+			flushed := make(chan struct{})
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				errCh <- func() error {
+					ww := tc.wrap(w)
+					rc := http.NewResponseController(ww)
+					if err := rc.SetReadDeadline(time.Now().Add(time.Minute)); err != nil {
+						return fmt.Errorf("SetReadDeadline: %w", err)
+					}
+					if err := rc.SetWriteDeadline(time.Now().Add(time.Minute)); err != nil {
+						return fmt.Errorf("SetWriteDeadline: %w", err)
+					}
+					if err := rc.EnableFullDuplex(); err != nil {
+						return fmt.Errorf("EnableFullDuplex: %w", err)
+					}
+					if _, err := fmt.Fprintf(ww, "hello"); err != nil {
+						return fmt.Errorf("Write: %w", err)
+					}
+					if err := rc.Flush(); err != nil {
+						return fmt.Errorf("Flush: %w", err)
+					}
+					return nil
+				}()
+				// Hold the response until the client received the flushed data.
+				<-flushed
+			}))
+			defer ts.Close()
+
+			resp, err := http.Get(ts.URL)
+			if err != nil {
+				close(flushed)
+				t.Fatalf("Error fetching test server: %s", err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			buf := make([]byte, 512)
+			n, err := resp.Body.Read(buf)
+			close(flushed)
+			if err := <-errCh; err != nil {
+				t.Fatal(err)
+			}
+			if err != nil {
+				t.Fatalf("Error reading from test server: %s", err)
+			}
+			if expect, got := "hello", string(buf[:n]); expect != got {
+				t.Fatalf("Unexpected message from test server.\nExpect:\t%s\nGot:\t%s", expect, got)
+			}
+		})
+	}
+}
+
+func TestResponseControllerHijack(t *testing.T) {
+	for _, tc := range responseControllerWrappers {
+		t.Run(tc.name, func(t *testing.T) {
+			errCh := make(chan error, 1)
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				conn, _, err := http.NewResponseController(tc.wrap(w)).Hijack()
+				if err != nil {
+					errCh <- fmt.Errorf("Error hijacking connection: %w", err)
+					return
+				}
+				defer func() { _ = conn.Close() }()
+				_, err = fmt.Fprintf(conn, "hello")
+				errCh <- err
+			}))
+			defer ts.Close()
+
+			client, err := net.Dial("tcp", strings.TrimPrefix(ts.URL, "http://"))
+			if err != nil {
+				t.Fatalf("Error trying to connect to the test server: %s", err)
+			}
+			defer func() { _ = client.Close() }()
+			fmt.Fprintf(client, "GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")
+			if err := <-errCh; err != nil {
+				t.Fatal(err)
+			}
+			line, err := bufio.NewReader(client).ReadString('o')
+			if err != nil {
+				t.Fatalf("Error reading from test server: %s", err)
+			}
+			if expect, got := "hello", line; expect != got {
+				t.Fatalf("Unexpected message from test server.\nExpect:\t%s\nGot:\t%s", expect, got)
+			}
+		})
+	}
+}
+
+func TestResponseControllerNotSupported(t *testing.T) {
+	for _, tc := range responseControllerWrappers {
+		t.Run(tc.name, func(t *testing.T) {
+			rc := http.NewResponseController(tc.wrap(httptest.NewRecorder()))
+			if err := rc.SetReadDeadline(time.Now()); !errors.Is(err, http.ErrNotSupported) {
+				t.Fatalf("Unexpected SetReadDeadline error.\nExpect:\t%v\nGot:\t%v", http.ErrNotSupported, err)
+			}
+			if err := rc.SetWriteDeadline(time.Now()); !errors.Is(err, http.ErrNotSupported) {
+				t.Fatalf("Unexpected SetWriteDeadline error.\nExpect:\t%v\nGot:\t%v", http.ErrNotSupported, err)
+			}
+			if _, _, err := rc.Hijack(); err == nil {
+				t.Fatal("Hijack on a recorder should fail.")
+			}
+			// The recorder is a Flusher.
+			if err := rc.Flush(); err != nil {
+				t.Fatalf("Unexpected Flush error: %s", err)
+			}
+		})
+	}
+}
+
+func TestResponseControllerFlushNotSupported(t *testing.T) {
+	w := NewResponseWriter(struct{ http.ResponseWriter }{httptest.NewRecorder()})
+	if err := http.NewResponseController(w).Flush(); !errors.Is(err, http.ErrNotSupported) {
+		t.Fatalf("Unexpected Flush error.\nExpect:\t%v\nGot:\t%v", http.ErrNotSupported, err)
+	}
+	assertInt(t, 0, w.Code())
+}
@@ -7,151 +7,194 @@ import (
 	"net"
 	"net/http"
 	"sync/atomic"
+	"time"
 )
 
-// TODO: add support for *net/http/httptest.ResponseRecorder. (Missing net/http.CloseNotifer interface).
+//go:generate go run gen_responsewriter.go
 
 // Common errors.
+//
+// Deprecated: not returned anymore. The ResponseWriter only implements net/http.Hijacker
+// and io.ReaderFrom when the underlying http.ResponseWriter does.
 var (
 	ErrNotHijacker   = errors.New("not a net/http.Hijacker")
 	ErrNotReaderFrom = errors.New("not an io.ReaderFrom")
 )
 
-// From io.stringWriter.
-//
-// NOTE: Why is this not exposed in the stdlib? Looks like it is reimplemented a lot:
-// - io.stringWriter
-// - strings.stringWriterIface
-// - net/http.writeStringer
-// - net/http.http2stringWriter
-// - github.com/creack/ehttp.writeStringer
-type writeStringer interface {
-	WriteString(string) (int, error)
-}
-
-// ResponseWriter extends http.ResponseWriter and exposes the http status code.
+// ResponseWriter extends http.ResponseWriter and exposes the http status code,
+// the number of bytes written and the timing of the response.
 type ResponseWriter interface {
 	http.ResponseWriter
-	Code() int
-}
-
-// http2responseWriter implements ehttp.ResponseWriter and exposes all *net/http.http2responseWriter interfaces.
-// stores the http status code as it gets se/sent.
-// As go go1.6.3:
-// - pointer type *net/http.http2responseWriter
-//   - implements io.Writer
-//   - implements io.stringWriter
-//   - implements CloseNotifier
-//   - implements Flusher
-//   - implements ResponseWriter
-//   - implements http2stringWriter
-//   - implements writeStringer
-//   - implements strings.stringWriterIface
-type http2responseWriter struct {
-	http.ResponseWriter
-	code *int32
+	Code() int                  // Http status code sent. 0 if not sent yet.
+	BytesWritten() int64        // Number of body bytes written.
+	HeaderWrittenAt() time.Time // Time the headers were sent. Zero if not sent yet.
+	FirstByteAt() time.Time     // Time the first body byte was written. Zero if nothing written yet.
+	Written() bool              // Whether or not the headers have been sent.
 }
 
-// response implements ehttp.ResponseWriter and exposes all *net/http.response interfaces.
+// responseWriter implements ehttp.ResponseWriter.
 // stores the http status code as it gets set/sent.
-// It has the same intefaces as *net/http.http2responseWriter plus net/http.Hijacker and io.ReaderFrom.
-// As of go1.6.3:
-// - pointer type *net/http.response
-//   - implements io.ReaderFrom
-//   - implements io.Writer
-//   - implements io.stringWriter
-//   - implements net/http.CloseNotifier
-//   - implements net/http.Flusher
-//   - implements net/http.Hijacker
-//   - implements net/http.ResponseWriter
-//   - implements net/http.http2stringWriter
-//   - implements net/http.writeStringer
-//   - implements strings.stringWriterIface
-type response struct {
-	*http2responseWriter
+// It only implements http.ResponseWriter, the optional interfaces are added by wrapResponseWriter.
+type responseWriter struct {
+	http.ResponseWriter
+	code        atomic.Int32              // Http status code sent.
+	written     atomic.Int64              // Number of body bytes written.
+	headerAt    atomic.Pointer[time.Time] // Time the headers were sent.
+	firstByteAt atomic.Pointer[time.Time] // Time the first body byte was written.
 }
 
 // NewResponseWriter instantiates a new ehttp ResponseWriter.
 //
-// If the underlying http.ResponseWriter implement net/http.Hijacker,
-// assume it is a *net/http.response and use *github.com/creack/ehttp.response, otherwise,
-// assume it is a *net/http.http2responseWriter and use *github.com/creack/ehttp.http2responseWriter.
+// The returned writer implements exactly the optional interfaces implemented by w among
+// net/http.Flusher, net/http.Hijacker, io.ReaderFrom, net/http.Pusher, net/http.CloseNotifier and io.StringWriter,
+// so type assertions on the returned writer give the same answers as on w.
 func NewResponseWriter(w http.ResponseWriter) ResponseWriter {
 	// If w is already an ehttp.ReponseWriter, return it.
 	if ww, ok := w.(ResponseWriter); ok {
 		return ww
 	}
-	ret := &http2responseWriter{
-		ResponseWriter: w,
-		code:           new(int32),
+	return wrapResponseWriter(&responseWriter{ResponseWriter: w})
+}
+
+// Code return the http code stored in the response writer.
+func (w *responseWriter) Code() int {
+	return int(w.code.Load())
+}
+
+// BytesWritten returns the number of body bytes written.
+func (w *responseWriter) BytesWritten() int64 {
+	return w.written.Load()
+}
+
+// HeaderWrittenAt returns the time the headers were sent.
+func (w *responseWriter) HeaderWrittenAt() time.Time {
+	if t := w.headerAt.Load(); t != nil {
+		return *t
 	}
-	if _, ok := w.(http.Hijacker); ok {
-		return &response{
-			http2responseWriter: ret,
-		}
+	return time.Time{}
+}
+
+// FirstByteAt returns the time the first body byte was written.
+func (w *responseWriter) FirstByteAt() time.Time {
+	if t := w.firstByteAt.Load(); t != nil {
+		return *t
 	}
-	return ret
+	return time.Time{}
 }
 
-// Code return the http code stored in the response writer.
-func (w http2responseWriter) Code() int {
-	return int(atomic.LoadInt32(w.code))
+// Written returns whether or not the headers have been sent.
+func (w *responseWriter) Written() bool {
+	return w.Code() != 0
+}
+
+// writeHeader stores the sent code and time. Returns false if the headers were already sent.
+func (w *responseWriter) writeHeader(code int) bool {
+	if !w.code.CompareAndSwap(0, int32(code)) {
+		return false
+	}
+	now := time.Now()
+	w.headerAt.Store(&now)
+	return true
+}
+
+// wrote accounts for the written body bytes and stores the time of the first one.
+func (w *responseWriter) wrote(n int64) {
+	if n <= 0 {
+		return
+	}
+	if w.written.Add(n) == n {
+		now := time.Now()
+		w.firstByteAt.CompareAndSwap(nil, &now)
+	}
 }
 
 // WriteHeader wraps underlying WriteHeader.
 // - sends the header only if not already sent.
 // - flag that the headers have been sent
 // - store the sent code
-func (w *http2responseWriter) WriteHeader(code int) {
-	if atomic.CompareAndSwapInt32(w.code, 0, int32(code)) {
+func (w *responseWriter) WriteHeader(code int) {
+	if w.writeHeader(code) {
 		w.ResponseWriter.WriteHeader(code)
 	}
 }
 
 // Write wraps the underlying Write and flag that the headers have been sent.
 // Following the net/http behavior, if no http status code has been set, assume http.StatusOK.
-func (w *http2responseWriter) Write(buf []byte) (int, error) {
-	atomic.CompareAndSwapInt32(w.code, 0, int32(http.StatusOK))
-	return w.ResponseWriter.Write(buf)
+func (w *responseWriter) Write(buf []byte) (int, error) {
+	w.writeHeader(http.StatusOK)
+	n, err := w.ResponseWriter.Write(buf)
+	w.wrote(int64(n))
+	return n, err
 }
 
-// WriteString wraps the underlying WriteString if available. Use ehttp.response.Write if not.
-// Flag the headers as sent.
-func (w *http2responseWriter) WriteString(str string) (int, error) {
-	atomic.CompareAndSwapInt32(w.code, 0, int32(http.StatusOK))
-	if ws, ok := w.ResponseWriter.(writeStringer); ok {
-		return ws.WriteString(str)
-	}
-	return w.Write([]byte(str))
+// Unwrap returns the underlying http.ResponseWriter.
+// Used by http.ResponseController to access the features of the underlying writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// flusher exposes the underlying net/http.Flusher interface.
+type flusher struct{ w *responseWriter }
+
+// Flush wraps the underlying Flush.
+// Following the net/http behavior, if no http status code has been set, assume http.StatusOK.
+func (f flusher) Flush() {
+	f.w.writeHeader(http.StatusOK)
+	f.w.ResponseWriter.(http.Flusher).Flush()
 }
 
-// Flush exposes the underlying net/http.Flusher interface. No-op if not a Flusher.
-func (w *http2responseWriter) Flush() {
-	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
-		flusher.Flush()
+// FlushError flushes the underlying writer, returning an error matching http.ErrNotSupported
+// if not supported. Used by http.ResponseController.
+func (f flusher) FlushError() error {
+	if err := http.NewResponseController(f.w.ResponseWriter).Flush(); err != nil {
+		return err
 	}
+	f.w.writeHeader(http.StatusOK)
+	return nil
 }
 
-// CloseNotify exposes the underlying net/http.CloseNotifier interface.
-// Panics if not available.
-func (w *http2responseWriter) CloseNotify() <-chan bool {
-	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+// hijacker exposes the underlying net/http.Hijacker interface.
+type hijacker struct{ w *responseWriter }
+
+// Hijack wraps the underlying Hijack.
+func (h hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return h.w.ResponseWriter.(http.Hijacker).Hijack()
 }
 
-// Hijack exposes the underlying net/http.Hijacker if available.
-// Errors out if not.
-func (w *response) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	if hijacker, ok := w.ResponseWriter.(http.Hijacker); ok {
-		return hijacker.Hijack()
-	}
-	return nil, nil, ErrNotHijacker
+// readerFrom exposes the underlying io.ReaderFrom interface.
+type readerFrom struct{ w *responseWriter }
+
+// ReadFrom wraps the underlying ReadFrom and flag that the headers have been sent.
+func (r readerFrom) ReadFrom(src io.Reader) (int64, error) {
+	r.w.writeHeader(http.StatusOK)
+	n, err := r.w.ResponseWriter.(io.ReaderFrom).ReadFrom(src)
+	r.w.wrote(n)
+	return n, err
 }
 
-// ReadFrom exposes the underlying io.ReaderFrom interface if available.
-// Errors out if not.
-func (w *response) ReadFrom(src io.Reader) (int64, error) {
-	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
-		return rf.ReadFrom(src)
-	}
-	return 0, ErrNotReaderFrom
+// pusher exposes the underlying net/http.Pusher interface.
+type pusher struct{ w *responseWriter }
+
+// Push wraps the underlying Push.
+func (p pusher) Push(target string, opts *http.PushOptions) error {
+	return p.w.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+// closeNotifier exposes the underlying net/http.CloseNotifier interface.
+type closeNotifier struct{ w *responseWriter }
+
+// CloseNotify wraps the underlying CloseNotify.
+func (c closeNotifier) CloseNotify() <-chan bool {
+	return c.w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+// stringWriter exposes the underlying io.StringWriter interface.
+type stringWriter struct{ w *responseWriter }
+
+// WriteString wraps the underlying WriteString and flag that the headers have been sent.
+func (s stringWriter) WriteString(str string) (int, error) {
+	s.w.writeHeader(http.StatusOK)
+	n, err := s.w.ResponseWriter.(io.StringWriter).WriteString(str)
+	s.w.wrote(int64(n))
+	return n, err
 }
@@ -0,0 +1,952 @@
+// Code generated by gen_responsewriter.go. DO NOT EDIT.
+
+package ehttp
+
+import (
+	"io"
+	"net/http"
+)
+
+// wrapResponseWriter returns w exposing exactly the optional interfaces implemented by the underlying http.ResponseWriter.
+func wrapResponseWriter(w *responseWriter) ResponseWriter {
+	var mask int
+	if _, ok := w.ResponseWriter.(http.Flusher); ok {
+		mask |= 1 << 0
+	}
+	if _, ok := w.ResponseWriter.(http.Hijacker); ok {
+		mask |= 1 << 1
+	}
+	if _, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		mask |= 1 << 2
+	}
+	if _, ok := w.ResponseWriter.(http.Pusher); ok {
+		mask |= 1 << 3
+	}
+	if _, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		mask |= 1 << 4
+	}
+	if _, ok := w.ResponseWriter.(io.StringWriter); ok {
+		mask |= 1 << 5
+	}
+	switch mask {
+	case 0: // None.
+		return w
+	case 1: // http.Flusher.
+		return struct {
+			*responseWriter
+			flusher
+		}{w, flusher{w}}
+	case 2: // http.Hijacker.
+		return struct {
+			*responseWriter
+			hijacker
+		}{w, hijacker{w}}
+	case 3: // http.Flusher, http.Hijacker.
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+		}{w, flusher{w}, hijacker{w}}
+	case 4: // io.ReaderFrom.
+		return struct {
+			*responseWriter
+			readerFrom
+		}{w, readerFrom{w}}
+	case 5: // http.Flusher, io.ReaderFrom.
+		return struct {
+			*responseWriter
+			flusher
+			readerFrom
+		}{w, flusher{w}, readerFrom{w}}
+	case 6: // http.Hijacker, io.ReaderFrom.
+		return struct {
+			*responseWriter
+			hijacker
+			readerFrom
+		}{w, hijacker{w}, readerFrom{w}}
+	case 7: // http.Flusher, http.Hijacker, io.ReaderFrom.
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			readerFrom
+		}{w, flusher{w}, hijacker{w}, readerFrom{w}}
+	case 8: // http.Pusher.
+		return struct {
+			*responseWriter
+			pusher
+		}{w, pusher{w}}
+	case 9: // http.Flusher, http.Pusher.
+		return struct {
+			*responseWriter
+			flusher
+			pusher
+		}{w, flusher{w}, pusher{w}}
+	case 10: // http.Hijacker, http.Pusher.
+		return struct {
+			*responseWriter
+			hijacker
+			pusher
+		}{w, hijacker{w}, pusher{w}}
+	case 11: // http.Flusher, http.Hijacker, http.Pusher.
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			pusher
+		}{w, flusher{w}, hijacker{w}, pusher{w}}
+	case 12: // io.ReaderFrom, http.Pusher.
+		return struct {
+			*responseWriter
+			readerFrom
+			pusher
+		}{w, readerFrom{w}, pusher{w}}
+	case 13: // http.Flusher, io.ReaderFrom, http.Pusher.
+		return struct {
+			*responseWriter
+			flusher
+			readerFrom
+			pusher
+		}{w, flusher{w}, readerFrom{w}, pusher{w}}
+	case 14: // http.Hijacker, io.ReaderFrom, http.Pusher.
+		return struct {
+			*responseWriter
+			hijacker
+			readerFrom
+			pusher
+		}{w, hijacker{w}, readerFrom{w}, pusher{w}}
+	case 15: // http.Flusher, http.Hijacker, io.ReaderFrom, http.Pusher.
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			readerFrom
+			pusher
+		}{w, flusher{w}, hijacker{w}, readerFrom{w}, pusher{w}}
+	case 16: // http.CloseNotifier.
+		return struct {
+			*responseWriter
+			closeNotifier
+		}{w, closeNotifier{w}}
+	case 17: // http.Flusher, http.CloseNotifier.
+		return struct {
+			*responseWriter
+			flusher
+			closeNotifier
+		}{w, flusher{w}, closeNotifier{w}}
+	case 18: // http.Hijacker, http.CloseNotifier.
+		return struct {
+			*responseWriter
+			hijacker
+			closeNotifier
+		}{w, hijacker{w}, closeNotifier{w}}
+	case 19: // http.Flusher, http.Hijacker, http.CloseNotifier.
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			closeNotifier
+		}{w, flusher{w}, hijacker{w}, closeNotifier{w}}
+	case 20: // io.ReaderFrom, http.CloseNotifier.
+		return struct {
+			*responseWriter
+			readerFrom
+			closeNotifier
+		}{w, readerFrom{w}, closeNotifier{w}}
+	case 21: // http.Flusher, io.ReaderFrom, http.CloseNotifier.
+		return struct {
+			*responseWriter
+			flusher
+			readerFrom
+			closeNotifier
+		}{w, flusher{w}, readerFrom{w}, closeNotifier{w}}
+	case 22: // http.Hijacker, io.ReaderFrom, http.CloseNotifier.
+		return struct {
+			*responseWriter
+			hijacker
+			readerFrom
+			closeNotifier
+		}{w, hijacker{w}, readerFrom{w}, closeNotifier{w}}
+	case 23: // http.Flusher, http.Hijacker, io.ReaderFrom, http.CloseNotifier.
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			readerFrom
+			closeNotifier
+		}{w, flusher{w}, hijacker{w}, readerFrom{w}, closeNotifier{w}}
+	case 24: // http.Pusher, http.CloseNotifier.
+		return struct {
+			*responseWriter
+			pusher
+			closeNotifier
+		}{w, pusher{w}, closeNotifier{w}}
+	case 25: // http.Flusher, http.Pusher, http.CloseNotifier.
+		return struct {
+			*responseWriter
+			flusher
+			pusher
+			closeNotifier
+		}{w, flusher{w}, pusher{w}, closeNotifier{w}}
+	case 26: // http.Hijacker, http.Pusher, http.CloseNotifier.
+		return struct {
+			*responseWriter
+			hijacker
+			pusher
+			closeNotifier
+		}{w, hijacker{w}, pusher{w}, closeNotifier{w}}
+	case 27: // http.Flusher, http.Hijacker, http.Pusher, http.CloseNotifier.
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			pusher
+			closeNotifier
+		}{w, flusher{w}, hijacker{w}, pusher{w}, closeNotifier{w}}
+	case 28: // io.ReaderFrom, http.Pusher, http.CloseNotifier.
+		return struct {
+			*responseWriter
+			readerFrom
+			pusher
+			closeNotifier
+		}{w, readerFrom{w}, pusher{w}, closeNotifier{w}}
+	case 29: // http.Flusher, io.ReaderFrom, http.Pusher, http.CloseNotifier.
+		return struct {
+			*responseWriter
+			flusher
+			readerFrom
+			pusher
+			closeNotifier
+		}{w, flusher{w}, readerFrom{w}, pusher{w}, closeNotifier{w}}
+	case 30: // http.Hijacker, io.ReaderFrom, http.Pusher, http.CloseNotifier.
+		return struct {
+			*responseWriter
+			hijacker
+			readerFrom
+			pusher
+			closeNotifier
+		}{w, hijacker{w}, readerFrom{w}, pusher{w}, closeNotifier{w}}
+	case 31: // http.Flusher, http.Hijacker, io.ReaderFrom, http.Pusher, http.CloseNotifier.
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			readerFrom
+			pusher
+			closeNotifier
+		}{w, flusher{w}, hijacker{w}, readerFrom{w}, pusher{w}, closeNotifier{w}}
+	case 32: // io.StringWriter.
+		return struct {
+			*responseWriter
+			stringWriter
+		}{w, stringWriter{w}}
+	case 33: // http.Flusher, io.StringWriter.
+		return struct {
+			*responseWriter
+			flusher
+			stringWriter
+		}{w, flusher{w}, stringWriter{w}}
+	case 34: // http.Hijacker, io.StringWriter.
+		return struct {
+			*responseWriter
+			hijacker
+			stringWriter
+		}{w, hijacker{w}, stringWriter{w}}
+	case 35: // http.Flusher, http.Hijacker, io.StringWriter.
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			stringWriter
+		}{w, flusher{w}, hijacker{w}, stringWriter{w}}
+	case 36: // io.ReaderFrom, io.StringWriter.
+		return struct {
+			*responseWriter
+			readerFrom
+			stringWriter
+		}{w, readerFrom{w}, stringWriter{w}}
+	case 37: // http.Flusher, io.ReaderFrom, io.StringWriter.
+		return struct {
+			*responseWriter
+			flusher
+			readerFrom
+			stringWriter
+		}{w, flusher{w}, readerFrom{w}, stringWriter{w}}
+	case 38: // http.Hijacker, io.ReaderFrom, io.StringWriter.
+		return struct {
+			*responseWriter
+			hijacker
+			readerFrom
+			stringWriter
+		}{w, hijacker{w}, readerFrom{w}, stringWriter{w}}
+	case 39: // http.Flusher, http.Hijacker, io.ReaderFrom, io.StringWriter.
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			readerFrom
+			stringWriter
+		}{w, flusher{w}, hijacker{w}, readerFrom{w}, stringWriter{w}}
+	case 40: // http.Pusher, io.StringWriter.
+		return struct {
+			*responseWriter
+			pusher
+			stringWriter
+		}{w, pusher{w}, stringWriter{w}}
+	case 41: // http.Flusher, http.Pusher, io.StringWriter.
+		return struct {
+			*responseWriter
+			flusher
+			pusher
+			stringWriter
+		}{w, flusher{w}, pusher{w}, stringWriter{w}}
+	case 42: // http.Hijacker, http.Pusher, io.StringWriter.
+		return struct {
+			*responseWriter
+			hijacker
+			pusher
+			stringWriter
+		}{w, hijacker{w}, pusher{w}, stringWriter{w}}
+	case 43: // http.Flusher, http.Hijacker, http.Pusher, io.StringWriter.
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			pusher
+			stringWriter
+		}{w, flusher{w}, hijacker{w}, pusher{w}, stringWriter{w}}
+	case 44: // io.ReaderFrom, http.Pusher, io.StringWriter.
+		return struct {
+			*responseWriter
+			readerFrom
+			pusher
+			stringWriter
+		}{w, readerFrom{w}, pusher{w}, stringWriter{w}}
+	case 45: // http.Flusher, io.ReaderFrom, http.Pusher, io.StringWriter.
+		return struct {
+			*responseWriter
+			flusher
+			readerFrom
+			pusher
+			stringWriter
+		}{w, flusher{w}, readerFrom{w}, pusher{w}, stringWriter{w}}
+	case 46: // http.Hijacker, io.ReaderFrom, http.Pusher, io.StringWriter.
+		return struct {
+			*responseWriter
+			hijacker
+			readerFrom
+			pusher
+			stringWriter
+		}{w, hijacker{w}, readerFrom{w}, pusher{w}, stringWriter{w}}
+	case 47: // http.Flusher, http.Hijacker, io.ReaderFrom, http.Pusher, io.StringWriter.
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			readerFrom
+			pusher
+			stringWriter
+		}{w, flusher{w}, hijacker{w}, readerFrom{w}, pusher{w}, stringWriter{w}}
+	case 48: // http.CloseNotifier, io.StringWriter.
+		return struct {
+			*responseWriter
+			closeNotifier
+			stringWriter
+		}{w, closeNotifier{w}, stringWriter{w}}
+	case 49: // http.Flusher, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*responseWriter
+			flusher
+			closeNotifier
+			stringWriter
+		}{w, flusher{w}, closeNotifier{w}, stringWriter{w}}
+	case 50: // http.Hijacker, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*responseWriter
+			hijacker
+			closeNotifier
+			stringWriter
+		}{w, hijacker{w}, closeNotifier{w}, stringWriter{w}}
+	case 51: // http.Flusher, http.Hijacker, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			closeNotifier
+			stringWriter
+		}{w, flusher{w}, hijacker{w}, closeNotifier{w}, stringWriter{w}}
+	case 52: // io.ReaderFrom, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*responseWriter
+			readerFrom
+			closeNotifier
+			stringWriter
+		}{w, readerFrom{w}, closeNotifier{w}, stringWriter{w}}
+	case 53: // http.Flusher, io.ReaderFrom, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*responseWriter
+			flusher
+			readerFrom
+			closeNotifier
+			stringWriter
+		}{w, flusher{w}, readerFrom{w}, closeNotifier{w}, stringWriter{w}}
+	case 54: // http.Hijacker, io.ReaderFrom, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*responseWriter
+			hijacker
+			readerFrom
+			closeNotifier
+			stringWriter
+		}{w, hijacker{w}, readerFrom{w}, closeNotifier{w}, stringWriter{w}}
+	case 55: // http.Flusher, http.Hijacker, io.ReaderFrom, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			readerFrom
+			closeNotifier
+			stringWriter
+		}{w, flusher{w}, hijacker{w}, readerFrom{w}, closeNotifier{w}, stringWriter{w}}
+	case 56: // http.Pusher, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*responseWriter
+			pusher
+			closeNotifier
+			stringWriter
+		}{w, pusher{w}, closeNotifier{w}, stringWriter{w}}
+	case 57: // http.Flusher, http.Pusher, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*responseWriter
+			flusher
+			pusher
+			closeNotifier
+			stringWriter
+		}{w, flusher{w}, pusher{w}, closeNotifier{w}, stringWriter{w}}
+	case 58: // http.Hijacker, http.Pusher, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*responseWriter
+			hijacker
+			pusher
+			closeNotifier
+			stringWriter
+		}{w, hijacker{w}, pusher{w}, closeNotifier{w}, stringWriter{w}}
+	case 59: // http.Flusher, http.Hijacker, http.Pusher, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			pusher
+			closeNotifier
+			stringWriter
+		}{w, flusher{w}, hijacker{w}, pusher{w}, closeNotifier{w}, stringWriter{w}}
+	case 60: // io.ReaderFrom, http.Pusher, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*responseWriter
+			readerFrom
+			pusher
+			closeNotifier
+			stringWriter
+		}{w, readerFrom{w}, pusher{w}, closeNotifier{w}, stringWriter{w}}
+	case 61: // http.Flusher, io.ReaderFrom, http.Pusher, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*responseWriter
+			flusher
+			readerFrom
+			pusher
+			closeNotifier
+			stringWriter
+		}{w, flusher{w}, readerFrom{w}, pusher{w}, closeNotifier{w}, stringWriter{w}}
+	case 62: // http.Hijacker, io.ReaderFrom, http.Pusher, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*responseWriter
+			hijacker
+			readerFrom
+			pusher
+			closeNotifier
+			stringWriter
+		}{w, hijacker{w}, readerFrom{w}, pusher{w}, closeNotifier{w}, stringWriter{w}}
+	case 63: // http.Flusher, http.Hijacker, io.ReaderFrom, http.Pusher, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			readerFrom
+			pusher
+			closeNotifier
+			stringWriter
+		}{w, flusher{w}, hijacker{w}, readerFrom{w}, pusher{w}, closeNotifier{w}, stringWriter{w}}
+	}
+	panic("unreachable")
+}
+
+// wrapBufferedResponseWriter returns w exposing exactly the optional interfaces implemented by the underlying http.ResponseWriter.
+func wrapBufferedResponseWriter(w *bufferedResponseWriter) ResponseWriter {
+	var mask int
+	if _, ok := w.w.(http.Flusher); ok {
+		mask |= 1 << 0
+	}
+	if _, ok := w.w.(http.Hijacker); ok {
+		mask |= 1 << 1
+	}
+	if _, ok := w.w.(io.ReaderFrom); ok {
+		mask |= 1 << 2
+	}
+	if _, ok := w.w.(http.Pusher); ok {
+		mask |= 1 << 3
+	}
+	if _, ok := w.w.(http.CloseNotifier); ok {
+		mask |= 1 << 4
+	}
+	if _, ok := w.w.(io.StringWriter); ok {
+		mask |= 1 << 5
+	}
+	switch mask {
+	case 0: // None.
+		return w
+	case 1: // http.Flusher.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+		}{w, bufferedFlusher{w}}
+	case 2: // http.Hijacker.
+		return struct {
+			*bufferedResponseWriter
+			bufferedHijacker
+		}{w, bufferedHijacker{w}}
+	case 3: // http.Flusher, http.Hijacker.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedHijacker
+		}{w, bufferedFlusher{w}, bufferedHijacker{w}}
+	case 4: // io.ReaderFrom.
+		return struct {
+			*bufferedResponseWriter
+			bufferedReaderFrom
+		}{w, bufferedReaderFrom{w}}
+	case 5: // http.Flusher, io.ReaderFrom.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedReaderFrom
+		}{w, bufferedFlusher{w}, bufferedReaderFrom{w}}
+	case 6: // http.Hijacker, io.ReaderFrom.
+		return struct {
+			*bufferedResponseWriter
+			bufferedHijacker
+			bufferedReaderFrom
+		}{w, bufferedHijacker{w}, bufferedReaderFrom{w}}
+	case 7: // http.Flusher, http.Hijacker, io.ReaderFrom.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedHijacker
+			bufferedReaderFrom
+		}{w, bufferedFlusher{w}, bufferedHijacker{w}, bufferedReaderFrom{w}}
+	case 8: // http.Pusher.
+		return struct {
+			*bufferedResponseWriter
+			bufferedPusher
+		}{w, bufferedPusher{w}}
+	case 9: // http.Flusher, http.Pusher.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedPusher
+		}{w, bufferedFlusher{w}, bufferedPusher{w}}
+	case 10: // http.Hijacker, http.Pusher.
+		return struct {
+			*bufferedResponseWriter
+			bufferedHijacker
+			bufferedPusher
+		}{w, bufferedHijacker{w}, bufferedPusher{w}}
+	case 11: // http.Flusher, http.Hijacker, http.Pusher.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedHijacker
+			bufferedPusher
+		}{w, bufferedFlusher{w}, bufferedHijacker{w}, bufferedPusher{w}}
+	case 12: // io.ReaderFrom, http.Pusher.
+		return struct {
+			*bufferedResponseWriter
+			bufferedReaderFrom
+			bufferedPusher
+		}{w, bufferedReaderFrom{w}, bufferedPusher{w}}
+	case 13: // http.Flusher, io.ReaderFrom, http.Pusher.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedReaderFrom
+			bufferedPusher
+		}{w, bufferedFlusher{w}, bufferedReaderFrom{w}, bufferedPusher{w}}
+	case 14: // http.Hijacker, io.ReaderFrom, http.Pusher.
+		return struct {
+			*bufferedResponseWriter
+			bufferedHijacker
+			bufferedReaderFrom
+			bufferedPusher
+		}{w, bufferedHijacker{w}, bufferedReaderFrom{w}, bufferedPusher{w}}
+	case 15: // http.Flusher, http.Hijacker, io.ReaderFrom, http.Pusher.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedHijacker
+			bufferedReaderFrom
+			bufferedPusher
+		}{w, bufferedFlusher{w}, bufferedHijacker{w}, bufferedReaderFrom{w}, bufferedPusher{w}}
+	case 16: // http.CloseNotifier.
+		return struct {
+			*bufferedResponseWriter
+			bufferedCloseNotifier
+		}{w, bufferedCloseNotifier{w}}
+	case 17: // http.Flusher, http.CloseNotifier.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedCloseNotifier
+		}{w, bufferedFlusher{w}, bufferedCloseNotifier{w}}
+	case 18: // http.Hijacker, http.CloseNotifier.
+		return struct {
+			*bufferedResponseWriter
+			bufferedHijacker
+			bufferedCloseNotifier
+		}{w, bufferedHijacker{w}, bufferedCloseNotifier{w}}
+	case 19: // http.Flusher, http.Hijacker, http.CloseNotifier.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedHijacker
+			bufferedCloseNotifier
+		}{w, bufferedFlusher{w}, bufferedHijacker{w}, bufferedCloseNotifier{w}}
+	case 20: // io.ReaderFrom, http.CloseNotifier.
+		return struct {
+			*bufferedResponseWriter
+			bufferedReaderFrom
+			bufferedCloseNotifier
+		}{w, bufferedReaderFrom{w}, bufferedCloseNotifier{w}}
+	case 21: // http.Flusher, io.ReaderFrom, http.CloseNotifier.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedReaderFrom
+			bufferedCloseNotifier
+		}{w, bufferedFlusher{w}, bufferedReaderFrom{w}, bufferedCloseNotifier{w}}
+	case 22: // http.Hijacker, io.ReaderFrom, http.CloseNotifier.
+		return struct {
+			*bufferedResponseWriter
+			bufferedHijacker
+			bufferedReaderFrom
+			bufferedCloseNotifier
+		}{w, bufferedHijacker{w}, bufferedReaderFrom{w}, bufferedCloseNotifier{w}}
+	case 23: // http.Flusher, http.Hijacker, io.ReaderFrom, http.CloseNotifier.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedHijacker
+			bufferedReaderFrom
+			bufferedCloseNotifier
+		}{w, bufferedFlusher{w}, bufferedHijacker{w}, bufferedReaderFrom{w}, bufferedCloseNotifier{w}}
+	case 24: // http.Pusher, http.CloseNotifier.
+		return struct {
+			*bufferedResponseWriter
+			bufferedPusher
+			bufferedCloseNotifier
+		}{w, bufferedPusher{w}, bufferedCloseNotifier{w}}
+	case 25: // http.Flusher, http.Pusher, http.CloseNotifier.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedPusher
+			bufferedCloseNotifier
+		}{w, bufferedFlusher{w}, bufferedPusher{w}, bufferedCloseNotifier{w}}
+	case 26: // http.Hijacker, http.Pusher, http.CloseNotifier.
+		return struct {
+			*bufferedResponseWriter
+			bufferedHijacker
+			bufferedPusher
+			bufferedCloseNotifier
+		}{w, bufferedHijacker{w}, bufferedPusher{w}, bufferedCloseNotifier{w}}
+	case 27: // http.Flusher, http.Hijacker, http.Pusher, http.CloseNotifier.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedHijacker
+			bufferedPusher
+			bufferedCloseNotifier
+		}{w, bufferedFlusher{w}, bufferedHijacker{w}, bufferedPusher{w}, bufferedCloseNotifier{w}}
+	case 28: // io.ReaderFrom, http.Pusher, http.CloseNotifier.
+		return struct {
+			*bufferedResponseWriter
+			bufferedReaderFrom
+			bufferedPusher
+			bufferedCloseNotifier
+		}{w, bufferedReaderFrom{w}, bufferedPusher{w}, bufferedCloseNotifier{w}}
+	case 29: // http.Flusher, io.ReaderFrom, http.Pusher, http.CloseNotifier.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedReaderFrom
+			bufferedPusher
+			bufferedCloseNotifier
+		}{w, bufferedFlusher{w}, bufferedReaderFrom{w}, bufferedPusher{w}, bufferedCloseNotifier{w}}
+	case 30: // http.Hijacker, io.ReaderFrom, http.Pusher, http.CloseNotifier.
+		return struct {
+			*bufferedResponseWriter
+			bufferedHijacker
+			bufferedReaderFrom
+			bufferedPusher
+			bufferedCloseNotifier
+		}{w, bufferedHijacker{w}, bufferedReaderFrom{w}, bufferedPusher{w}, bufferedCloseNotifier{w}}
+	case 31: // http.Flusher, http.Hijacker, io.ReaderFrom, http.Pusher, http.CloseNotifier.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedHijacker
+			bufferedReaderFrom
+			bufferedPusher
+			bufferedCloseNotifier
+		}{w, bufferedFlusher{w}, bufferedHijacker{w}, bufferedReaderFrom{w}, bufferedPusher{w}, bufferedCloseNotifier{w}}
+	case 32: // io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedStringWriter
+		}{w, bufferedStringWriter{w}}
+	case 33: // http.Flusher, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedStringWriter
+		}{w, bufferedFlusher{w}, bufferedStringWriter{w}}
+	case 34: // http.Hijacker, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedHijacker
+			bufferedStringWriter
+		}{w, bufferedHijacker{w}, bufferedStringWriter{w}}
+	case 35: // http.Flusher, http.Hijacker, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedHijacker
+			bufferedStringWriter
+		}{w, bufferedFlusher{w}, bufferedHijacker{w}, bufferedStringWriter{w}}
+	case 36: // io.ReaderFrom, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedReaderFrom
+			bufferedStringWriter
+		}{w, bufferedReaderFrom{w}, bufferedStringWriter{w}}
+	case 37: // http.Flusher, io.ReaderFrom, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedReaderFrom
+			bufferedStringWriter
+		}{w, bufferedFlusher{w}, bufferedReaderFrom{w}, bufferedStringWriter{w}}
+	case 38: // http.Hijacker, io.ReaderFrom, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedHijacker
+			bufferedReaderFrom
+			bufferedStringWriter
+		}{w, bufferedHijacker{w}, bufferedReaderFrom{w}, bufferedStringWriter{w}}
+	case 39: // http.Flusher, http.Hijacker, io.ReaderFrom, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedHijacker
+			bufferedReaderFrom
+			bufferedStringWriter
+		}{w, bufferedFlusher{w}, bufferedHijacker{w}, bufferedReaderFrom{w}, bufferedStringWriter{w}}
+	case 40: // http.Pusher, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedPusher
+			bufferedStringWriter
+		}{w, bufferedPusher{w}, bufferedStringWriter{w}}
+	case 41: // http.Flusher, http.Pusher, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedPusher
+			bufferedStringWriter
+		}{w, bufferedFlusher{w}, bufferedPusher{w}, bufferedStringWriter{w}}
+	case 42: // http.Hijacker, http.Pusher, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedHijacker
+			bufferedPusher
+			bufferedStringWriter
+		}{w, bufferedHijacker{w}, bufferedPusher{w}, bufferedStringWriter{w}}
+	case 43: // http.Flusher, http.Hijacker, http.Pusher, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedHijacker
+			bufferedPusher
+			bufferedStringWriter
+		}{w, bufferedFlusher{w}, bufferedHijacker{w}, bufferedPusher{w}, bufferedStringWriter{w}}
+	case 44: // io.ReaderFrom, http.Pusher, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedReaderFrom
+			bufferedPusher
+			bufferedStringWriter
+		}{w, bufferedReaderFrom{w}, bufferedPusher{w}, bufferedStringWriter{w}}
+	case 45: // http.Flusher, io.ReaderFrom, http.Pusher, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedReaderFrom
+			bufferedPusher
+			bufferedStringWriter
+		}{w, bufferedFlusher{w}, bufferedReaderFrom{w}, bufferedPusher{w}, bufferedStringWriter{w}}
+	case 46: // http.Hijacker, io.ReaderFrom, http.Pusher, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedHijacker
+			bufferedReaderFrom
+			bufferedPusher
+			bufferedStringWriter
+		}{w, bufferedHijacker{w}, bufferedReaderFrom{w}, bufferedPusher{w}, bufferedStringWriter{w}}
+	case 47: // http.Flusher, http.Hijacker, io.ReaderFrom, http.Pusher, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedHijacker
+			bufferedReaderFrom
+			bufferedPusher
+			bufferedStringWriter
+		}{w, bufferedFlusher{w}, bufferedHijacker{w}, bufferedReaderFrom{w}, bufferedPusher{w}, bufferedStringWriter{w}}
+	case 48: // http.CloseNotifier, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedCloseNotifier
+			bufferedStringWriter
+		}{w, bufferedCloseNotifier{w}, bufferedStringWriter{w}}
+	case 49: // http.Flusher, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedCloseNotifier
+			bufferedStringWriter
+		}{w, bufferedFlusher{w}, bufferedCloseNotifier{w}, bufferedStringWriter{w}}
+	case 50: // http.Hijacker, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedHijacker
+			bufferedCloseNotifier
+			bufferedStringWriter
+		}{w, bufferedHijacker{w}, bufferedCloseNotifier{w}, bufferedStringWriter{w}}
+	case 51: // http.Flusher, http.Hijacker, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedHijacker
+			bufferedCloseNotifier
+			bufferedStringWriter
+		}{w, bufferedFlusher{w}, bufferedHijacker{w}, bufferedCloseNotifier{w}, bufferedStringWriter{w}}
+	case 52: // io.ReaderFrom, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedReaderFrom
+			bufferedCloseNotifier
+			bufferedStringWriter
+		}{w, bufferedReaderFrom{w}, bufferedCloseNotifier{w}, bufferedStringWriter{w}}
+	case 53: // http.Flusher, io.ReaderFrom, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedReaderFrom
+			bufferedCloseNotifier
+			bufferedStringWriter
+		}{w, bufferedFlusher{w}, bufferedReaderFrom{w}, bufferedCloseNotifier{w}, bufferedStringWriter{w}}
+	case 54: // http.Hijacker, io.ReaderFrom, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedHijacker
+			bufferedReaderFrom
+			bufferedCloseNotifier
+			bufferedStringWriter
+		}{w, bufferedHijacker{w}, bufferedReaderFrom{w}, bufferedCloseNotifier{w}, bufferedStringWriter{w}}
+	case 55: // http.Flusher, http.Hijacker, io.ReaderFrom, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedHijacker
+			bufferedReaderFrom
+			bufferedCloseNotifier
+			bufferedStringWriter
+		}{w, bufferedFlusher{w}, bufferedHijacker{w}, bufferedReaderFrom{w}, bufferedCloseNotifier{w}, bufferedStringWriter{w}}
+	case 56: // http.Pusher, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedPusher
+			bufferedCloseNotifier
+			bufferedStringWriter
+		}{w, bufferedPusher{w}, bufferedCloseNotifier{w}, bufferedStringWriter{w}}
+	case 57: // http.Flusher, http.Pusher, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedPusher
+			bufferedCloseNotifier
+			bufferedStringWriter
+		}{w, bufferedFlusher{w}, bufferedPusher{w}, bufferedCloseNotifier{w}, bufferedStringWriter{w}}
+	case 58: // http.Hijacker, http.Pusher, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedHijacker
+			bufferedPusher
+			bufferedCloseNotifier
+			bufferedStringWriter
+		}{w, bufferedHijacker{w}, bufferedPusher{w}, bufferedCloseNotifier{w}, bufferedStringWriter{w}}
+	case 59: // http.Flusher, http.Hijacker, http.Pusher, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedHijacker
+			bufferedPusher
+			bufferedCloseNotifier
+			bufferedStringWriter
+		}{w, bufferedFlusher{w}, bufferedHijacker{w}, bufferedPusher{w}, bufferedCloseNotifier{w}, bufferedStringWriter{w}}
+	case 60: // io.ReaderFrom, http.Pusher, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedReaderFrom
+			bufferedPusher
+			bufferedCloseNotifier
+			bufferedStringWriter
+		}{w, bufferedReaderFrom{w}, bufferedPusher{w}, bufferedCloseNotifier{w}, bufferedStringWriter{w}}
+	case 61: // http.Flusher, io.ReaderFrom, http.Pusher, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedReaderFrom
+			bufferedPusher
+			bufferedCloseNotifier
+			bufferedStringWriter
+		}{w, bufferedFlusher{w}, bufferedReaderFrom{w}, bufferedPusher{w}, bufferedCloseNotifier{w}, bufferedStringWriter{w}}
+	case 62: // http.Hijacker, io.ReaderFrom, http.Pusher, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedHijacker
+			bufferedReaderFrom
+			bufferedPusher
+			bufferedCloseNotifier
+			bufferedStringWriter
+		}{w, bufferedHijacker{w}, bufferedReaderFrom{w}, bufferedPusher{w}, bufferedCloseNotifier{w}, bufferedStringWriter{w}}
+	case 63: // http.Flusher, http.Hijacker, io.ReaderFrom, http.Pusher, http.CloseNotifier, io.StringWriter.
+		return struct {
+			*bufferedResponseWriter
+			bufferedFlusher
+			bufferedHijacker
+			bufferedReaderFrom
+			bufferedPusher
+			bufferedCloseNotifier
+			bufferedStringWriter
+		}{w, bufferedFlusher{w}, bufferedHijacker{w}, bufferedReaderFrom{w}, bufferedPusher{w}, bufferedCloseNotifier{w}, bufferedStringWriter{w}}
+	}
+	panic("unreachable")
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -12,34 +13,92 @@ import (
 	"time"
 )
 
-// Enforce that *response and *http2responseWriter implements the proper interaces.
+// Enforce that *responseWriter implements the proper interaces.
 var (
-	_ io.ReaderFrom       = (*response)(nil)
-	_ io.Writer           = (*response)(nil)
-	_ writeStringer       = (*response)(nil)
-	_ http.CloseNotifier  = (*response)(nil)
-	_ http.Flusher        = (*response)(nil)
-	_ http.Hijacker       = (*response)(nil)
-	_ http.ResponseWriter = (*response)(nil)
-
-	_ io.Writer           = (*http2responseWriter)(nil)
-	_ writeStringer       = (*http2responseWriter)(nil)
-	_ http.CloseNotifier  = (*http2responseWriter)(nil)
-	_ http.Flusher        = (*http2responseWriter)(nil)
-	_ http.ResponseWriter = (*http2responseWriter)(nil)
+	_ io.Writer           = (*responseWriter)(nil)
+	_ http.ResponseWriter = (*responseWriter)(nil)
+	_ ResponseWriter      = (*responseWriter)(nil)
 )
 
-func TestResponseWriterHijackNotHijcaker(t *testing.T) {
-	respW := httptest.NewRecorder()
-	r := &response{
-		&http2responseWriter{
-			ResponseWriter: respW,
-		},
+// optionalInterfaces lists the optional interfaces preserved by NewResponseWriter.
+var optionalInterfaces = []struct {
+	name   string
+	assert func(http.ResponseWriter) bool
+}{
+	{"http.Flusher", func(w http.ResponseWriter) bool { _, ok := w.(http.Flusher); return ok }},
+	{"http.Hijacker", func(w http.ResponseWriter) bool { _, ok := w.(http.Hijacker); return ok }},
+	{"io.ReaderFrom", func(w http.ResponseWriter) bool { _, ok := w.(io.ReaderFrom); return ok }},
+	{"http.Pusher", func(w http.ResponseWriter) bool { _, ok := w.(http.Pusher); return ok }},
+	{"http.CloseNotifier", func(w http.ResponseWriter) bool { _, ok := w.(http.CloseNotifier); return ok }},
+	{"io.StringWriter", func(w http.ResponseWriter) bool { _, ok := w.(io.StringWriter); return ok }},
+}
+
+// interfacesDiff lists the optional interfaces for which the type assertions on the wrapped writers
+// (NewResponseWriter and the buffered writer) do not give the same answers as on the original.
+func interfacesDiff(orig http.ResponseWriter) []string {
+	var diff []string
+	for name, w := range map[string]http.ResponseWriter{
+		"":          NewResponseWriter(orig),
+		"buffered ": wrapBufferedResponseWriter(newBufferedResponseWriter(NewResponseWriter(orig), 1024)),
+	} {
+		for _, iface := range optionalInterfaces {
+			if iface.assert(orig) != iface.assert(w) {
+				diff = append(diff, name+iface.name)
+			}
+		}
 	}
-	if _, _, err := r.Hijack(); err == nil {
-		t.Fatalf("*net/http/httptest.ResonseRecoreded hijack should fail.")
+	return diff
+}
+
+func TestResponseWriterInterfaces(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	for _, w := range []http.ResponseWriter{
+		recorder,
+		struct{ http.ResponseWriter }{recorder},
+		struct {
+			http.ResponseWriter
+			http.Pusher
+			io.ReaderFrom
+		}{recorder, nil, nil},
+		struct {
+			http.ResponseWriter
+			http.Hijacker
+			http.CloseNotifier
+		}{recorder, nil, nil},
+	} {
+		if diff := interfacesDiff(w); len(diff) != 0 {
+			t.Errorf("Interfaces not preserved for %T: %v", w, diff)
+		}
 	}
+}
+
+func TestResponseWriterInterfacesServer(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if diff := interfacesDiff(w); len(diff) != 0 {
+			http.Error(w, fmt.Sprintf("interfaces not preserved for %T: %v", w, diff), http.StatusInternalServerError)
+		}
+	}))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
 
+	tlsConfig := ts.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	tlsConfig.NextProtos = []string{"http/1.1"}
+	http1 := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	for proto, client := range map[int]*http.Client{1: http1, 2: ts.Client()} {
+		resp, err := client.Get(ts.URL)
+		if err != nil {
+			t.Fatalf("Error fetching test server: %s", err)
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertInt(t, proto, resp.ProtoMajor)
+		assertInt(t, http.StatusOK, resp.StatusCode)
+		assertString(t, "", string(body))
+	}
 }
 
 func TestResponseWriterHijack(t *testing.T) {
@@ -69,6 +128,77 @@ func TestResponseWriterHijack(t *testing.T) {
 	}
 }
 
+func TestResponseWriterHijackBuffered(t *testing.T) {
+	mux := New(WithBuffering(10))
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) error {
+		w.Header().Set("X-Test", "test")
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return fmt.Errorf("Error hijacking connection: %s (%T)", err, w)
+		}
+		fmt.Fprintf(conn, "hello")
+		return conn.Close()
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	client, err := net.Dial("tcp", strings.TrimPrefix(ts.URL, "http://"))
+	if err != nil {
+		t.Fatalf("Error trying to connect to the test server: %s", err)
+	}
+	defer func() { _ = client.Close() }()
+	fmt.Fprintf(client, "GET / HTTP/1.1\r\nHost: localhost\r\nConnection: Upgrade\r\n\r\n")
+	buf, err := ioutil.ReadAll(client)
+	if err != nil {
+		t.Fatalf("Error reading from test server: %s", err)
+	}
+	if expect, got := "hello", string(buf); expect != got {
+		t.Fatalf("Unexpected message from test server.\nExpect:\t%s\nGot:\t%s", expect, got)
+	}
+}
+
+// readerFromRecorder is a recorder implementing io.ReaderFrom.
+type readerFromRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (r readerFromRecorder) ReadFrom(src io.Reader) (int64, error) {
+	return io.Copy(struct{ io.Writer }{r.ResponseRecorder}, src)
+}
+
+func TestResponseWriterBufferedOptional(t *testing.T) {
+	rec := httptest.NewRecorder()
+	bw := newBufferedResponseWriter(NewResponseWriter(readerFromRecorder{rec}), 8)
+	w := wrapBufferedResponseWriter(bw)
+
+	// Buffered until the limit.
+	if _, err := w.(io.StringWriter).WriteString("hello"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.(io.ReaderFrom).ReadFrom(strings.NewReader("!")); err != nil {
+		t.Fatal(err)
+	}
+	assertString(t, "", rec.Body.String())
+	assertInt(t, 0, int(w.BytesWritten()))
+
+	// Flushing commits the response.
+	w.(http.Flusher).Flush()
+	assertString(t, "hello!", rec.Body.String())
+	if !rec.Flushed {
+		t.Fatal("The underlying writer should be flushed")
+	}
+
+	// Streaming once committed.
+	if _, err := w.(io.StringWriter).WriteString(" world"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.(io.ReaderFrom).ReadFrom(strings.NewReader("!")); err != nil {
+		t.Fatal(err)
+	}
+	assertString(t, "hello! world!", rec.Body.String())
+	assertInt(t, len("hello! world!"), int(w.BytesWritten()))
+}
+
 func TestResponseWriterFlush(t *testing.T) {
 	ch := make(chan int)
 	ts := httptest.NewServer(HandlerFunc(func(w http.ResponseWriter, req *http.Request) error {
@@ -161,7 +291,7 @@ For:
 
 func TestResponseWriterWriteString(t *testing.T) {
 	ts := httptest.NewServer(HandlerFunc(func(w http.ResponseWriter, req *http.Request) error {
-		if _, ok := w.(writeStringer); !ok {
+		if _, ok := w.(io.StringWriter); !ok {
 			return fmt.Errorf("responseWriter is not a writeString: %T", w)
 		}
 		_, err := io.WriteString(w, "hello")
@@ -220,27 +350,19 @@ func TestResponseWriterReadFrom(t *testing.T) {
 
 func TestResponseWriterNotReadFrom(t *testing.T) {
 	ts := httptest.NewServer(HandlerFunc(func(w http.ResponseWriter, req *http.Request) error {
-		// Change the underlying type to http2responseWriter which is not an io.ReaderFrom.
-		w = &response{
-			&http2responseWriter{
-				ResponseWriter: &http2responseWriter{
-					ResponseWriter: w,
-				},
-			},
-		}
-		if _, ok := w.(io.ReaderFrom); !ok {
-			return fmt.Errorf("responseWriter is not a readerFrom: (%T)", w)
+		// Hide the underlying io.ReaderFrom.
+		w = NewResponseWriter(struct{ http.ResponseWriter }{w})
+		if _, ok := w.(io.ReaderFrom); ok {
+			return fmt.Errorf("responseWriter should not be a readerFrom: (%T)", w)
 		}
 		rr, ww := io.Pipe()
 		go func() {
 			_, _ = io.WriteString(ww, "hello")
 			_ = ww.Close()
 		}()
+		// Even though not implemented, it should work using io.Writer.
 		_, err := io.Copy(w, rr)
-		if err != ErrNotReaderFrom {
-			return fmt.Errorf("Expected error when using io.ReaderFrom while not being implemented. Got: %v", err)
-		}
-		return nil
+		return err
 	}))
 	defer ts.Close()
 
@@ -250,25 +372,22 @@ func TestResponseWriterNotReadFrom(t *testing.T) {
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusOK {
-		buf := bytes.NewBuffer(nil)
-		_, _ = io.Copy(buf, resp.Body)
-		t.Fatalf("Unexpected status code: %d (%s)", resp.StatusCode, buf)
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
 	}
+	assertInt(t, http.StatusOK, resp.StatusCode)
+	assertString(t, "hello", string(body))
 }
 
-// dummyResponseWriter implements ehttp.ResponseWriter, but does not implement writeStringer.
+// dummyResponseWriter implements http.ResponseWriter, but does not implement io.StringWriter.
 type dummyResponseWriter struct{ http.ResponseWriter }
 
-func (*dummyResponseWriter) Code() int { return 0 }
-
 func TestResponseWriterNotWriteString(t *testing.T) {
 	ts := httptest.NewServer(HandlerFunc(func(w http.ResponseWriter, req *http.Request) error {
-		w = &response{
-			&http2responseWriter{
-				ResponseWriter: dummyResponseWriter{ResponseWriter: w},
-				code:           new(int32),
-			},
+		w = NewResponseWriter(dummyResponseWriter{ResponseWriter: w})
+		if _, ok := w.(io.StringWriter); ok {
+			return fmt.Errorf("responseWriter should not be a stringWriter: (%T)", w)
 		}
 		// Event though not implemented, it should work using io.Writer.
 		_, err := io.WriteString(w, "hello")
@@ -306,3 +425,100 @@ func TestNewResponseWriter(t *testing.T) {
 		t.Fatalf("NewResposneWriter called with a regular http.ResponseWriter should wrap it and return a new object")
 	}
 }
+
+// assertTracking checks the ResponseWriter counters after the headers and the given number of bytes are sent.
+func assertTracking(t *testing.T, w ResponseWriter, start time.Time, bytes int64) {
+	t.Helper()
+	if !w.Written() {
+		t.Fatal("Headers should be flagged as written")
+	}
+	if got := w.BytesWritten(); got != bytes {
+		t.Fatalf("Unexpected bytes written.\nExpect:\t%d\nGot:\t%d", bytes, got)
+	}
+	if w.HeaderWrittenAt().Before(start) {
+		t.Fatalf("Unexpected header time: %s", w.HeaderWrittenAt())
+	}
+	if w.FirstByteAt().Before(w.HeaderWrittenAt()) {
+		t.Fatalf("First byte %s should be after the header %s", w.FirstByteAt(), w.HeaderWrittenAt())
+	}
+}
+
+func TestResponseWriterTracking(t *testing.T) {
+	var (
+		start time.Time
+		ww    ResponseWriter
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start = time.Now()
+		ww = NewResponseWriter(w)
+		ww.WriteHeader(http.StatusAccepted)
+		_, _ = ww.Write([]byte("hello"))
+		_, _ = io.WriteString(ww, " world")
+		_, _ = ww.(io.ReaderFrom).ReadFrom(strings.NewReader("!"))
+		_, _ = ww.Write(nil)
+	}))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("Error fetching test server: %s", err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, http.StatusAccepted, resp.StatusCode)
+	assertString(t, "hello world!", string(body))
+	if _, ok := ww.(http.Hijacker); !ok {
+		t.Fatalf("Unexpected writer type %T", ww)
+	}
+	assertTracking(t, ww, start, 12)
+
+	// Nothing written yet.
+	w := NewResponseWriter(httptest.NewRecorder())
+	if w.Written() || w.BytesWritten() != 0 || !w.HeaderWrittenAt().IsZero() || !w.FirstByteAt().IsZero() {
+		t.Fatal("New writer should not have anything written")
+	}
+	w.WriteHeader(http.StatusAccepted)
+	if !w.Written() || w.HeaderWrittenAt().IsZero() || !w.FirstByteAt().IsZero() {
+		t.Fatal("Only the headers should be flagged as written")
+	}
+}
+
+func TestResponseWriterTrackingRecorder(t *testing.T) {
+	// The recorder is an io.StringWriter.
+	start := time.Now()
+	w := NewResponseWriter(httptest.NewRecorder())
+	if _, ok := w.(io.StringWriter); !ok {
+		t.Fatalf("Unexpected writer type %T", w)
+	}
+	_, _ = io.WriteString(w, "hello")
+	assertTracking(t, w, start, 5)
+	assertInt(t, http.StatusOK, w.Code())
+
+	// ReadFrom marks the headers as written.
+	start = time.Now()
+	r := NewResponseWriter(struct {
+		http.ResponseWriter
+		io.ReaderFrom
+	}{ResponseWriter: httptest.NewRecorder(), ReaderFrom: bytes.NewBuffer(nil)})
+	if _, err := r.(io.ReaderFrom).ReadFrom(strings.NewReader("hello")); err != nil {
+		t.Fatal(err)
+	}
+	assertTracking(t, r, start, 5)
+}
+
+func TestResponseWriterTrackingBuffered(t *testing.T) {
+	start := time.Now()
+	w := NewResponseWriter(httptest.NewRecorder())
+	bw := newBufferedResponseWriter(w, 5)
+	_, _ = bw.Write([]byte("hello"))
+	if bw.Written() || bw.BytesWritten() != 0 {
+		t.Fatal("Buffered bytes should not be accounted for")
+	}
+	_, _ = bw.Write([]byte(" world"))
+	assertTracking(t, bw, start, 11)
+	assertString(t, w.HeaderWrittenAt().String(), bw.HeaderWrittenAt().String())
+	assertString(t, w.FirstByteAt().String(), bw.FirstByteAt().String())
+}
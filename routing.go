@@ -0,0 +1,108 @@
+package ehttp
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Get registers the handler for the given pattern and the GET method (and HEAD).
+// The pattern follows the net/http.ServeMux syntax, without the method, e.g. "/users/{id}".
+func (sm *ServeMux) Get(pattern string, handler HandlerFunc) {
+	sm.HandleFunc(http.MethodGet+" "+pattern, handler)
+}
+
+// Post registers the handler for the given pattern and the POST method.
+func (sm *ServeMux) Post(pattern string, handler HandlerFunc) {
+	sm.HandleFunc(http.MethodPost+" "+pattern, handler)
+}
+
+// Put registers the handler for the given pattern and the PUT method.
+func (sm *ServeMux) Put(pattern string, handler HandlerFunc) {
+	sm.HandleFunc(http.MethodPut+" "+pattern, handler)
+}
+
+// Delete registers the handler for the given pattern and the DELETE method.
+func (sm *ServeMux) Delete(pattern string, handler HandlerFunc) {
+	sm.HandleFunc(http.MethodDelete+" "+pattern, handler)
+}
+
+// Patch registers the handler for the given pattern and the PATCH method.
+func (sm *ServeMux) Patch(pattern string, handler HandlerFunc) {
+	sm.HandleFunc(http.MethodPatch+" "+pattern, handler)
+}
+
+// PathValueType is the set of types supported by PathValue.
+type PathValueType interface {
+	string | int | int64 | uint | uint64 | float64 | bool
+}
+
+// PathValue returns the value of the named path wildcard converted to T.
+// If the value is missing or can't be converted, a http.StatusBadRequest *Error is returned.
+//
+//	id, err := ehttp.PathValue[int](req, "id")
+//	if err != nil {
+//		return err
+//	}
+func PathValue[T PathValueType](req *http.Request, name string) (T, error) {
+	var v T
+	raw := req.PathValue(name)
+	if raw == "" {
+		return v, NewErrorf(http.StatusBadRequest, "missing path value %q", name)
+	}
+
+	var err error
+	switch p := interface{}(&v).(type) {
+	case *string:
+		*p = raw
+	case *int:
+		*p, err = strconv.Atoi(raw)
+	case *int64:
+		*p, err = strconv.ParseInt(raw, 10, 64)
+	case *uint:
+		var u uint64
+		u, err = strconv.ParseUint(raw, 10, 0)
+		*p = uint(u)
+	case *uint64:
+		*p, err = strconv.ParseUint(raw, 10, 64)
+	case *float64:
+		*p, err = strconv.ParseFloat(raw, 64)
+	case *bool:
+		*p, err = strconv.ParseBool(raw)
+	}
+	if err != nil {
+		var zero T
+		return zero, NewPublicError(http.StatusBadRequest, fmt.Sprintf("invalid path value %q: expected %T", name, zero), err)
+	}
+	return v, nil
+}
+
+// statusInterceptor intercepts the error status sent by the underlying *net/http.ServeMux
+// for unmatched requests (404, 405) so the error can be sent via HandleError.
+// The headers, like Allow, are kept.
+type statusInterceptor struct {
+	http.ResponseWriter
+	code int
+}
+
+// WriteHeader records the error status codes and forwards the others.
+func (i *statusInterceptor) WriteHeader(code int) {
+	if code >= http.StatusBadRequest && i.code == 0 {
+		i.code = code
+		return
+	}
+	i.ResponseWriter.WriteHeader(code)
+}
+
+// Write discards the body of the intercepted errors.
+func (i *statusInterceptor) Write(buf []byte) (int, error) {
+	if i.code != 0 {
+		return len(buf), nil
+	}
+	return i.ResponseWriter.Write(buf)
+}
+
+// Unwrap returns the underlying http.ResponseWriter. Used by http.ResponseController.
+func (i *statusInterceptor) Unwrap() http.ResponseWriter {
+	return i.ResponseWriter
+}
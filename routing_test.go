@@ -0,0 +1,121 @@
+package ehttp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMethodRouting(t *testing.T) {
+	mux := New()
+	mux.Get("/users/{id}", func(w http.ResponseWriter, req *http.Request) error {
+		id, err := PathValue[int](req, "id")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "get %d", id)
+		return nil
+	})
+	mux.Post("/users", func(w http.ResponseWriter, req *http.Request) error {
+		fmt.Fprintf(w, "post")
+		return nil
+	})
+	mux.Put("/users/{id}", func(w http.ResponseWriter, req *http.Request) error {
+		fmt.Fprintf(w, "put")
+		return nil
+	})
+	mux.Delete("/users/{id}", func(w http.ResponseWriter, req *http.Request) error {
+		fmt.Fprintf(w, "delete")
+		return nil
+	})
+	mux.Patch("/users/{id}", func(w http.ResponseWriter, req *http.Request) error {
+		fmt.Fprintf(w, "patch")
+		return nil
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		status int
+		body   string
+	}{
+		{method: "GET", path: "/users/42", status: http.StatusOK, body: "get 42"},
+		{method: "POST", path: "/users", status: http.StatusOK, body: "post"},
+		{method: "PUT", path: "/users/42", status: http.StatusOK, body: "put"},
+		{method: "DELETE", path: "/users/42", status: http.StatusOK, body: "delete"},
+		{method: "PATCH", path: "/users/42", status: http.StatusOK, body: "patch"},
+		{method: "GET", path: "/users/abc", status: http.StatusBadRequest, body: `{"errors":["invalid path value \"id\": expected int"]}` + "\n"},
+		{method: "GET", path: "/unknown", status: http.StatusNotFound, body: `{"errors":["Not Found"]}` + "\n"},
+		{method: "POST", path: "/users/42", status: http.StatusMethodNotAllowed, body: `{"errors":["Method Not Allowed"]}` + "\n"},
+	} {
+		req, err := http.NewRequest(tc.method, ts.URL+tc.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Error requesting test server: %s", err)
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Error reading response body: %s", err)
+		}
+		assertInt(t, tc.status, resp.StatusCode)
+		assertString(t, tc.body, string(body))
+		if tc.status >= http.StatusBadRequest {
+			assertString(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+		}
+		if tc.status == http.StatusMethodNotAllowed {
+			if allow := resp.Header.Get("Allow"); !strings.Contains(allow, "GET") || !strings.Contains(allow, "PUT") {
+				t.Errorf("Unexpected Allow header: %q", allow)
+			}
+		}
+	}
+}
+
+func TestPathValue(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.SetPathValue("str", "abc")
+	req.SetPathValue("int", "-42")
+	req.SetPathValue("uint", "42")
+	req.SetPathValue("float", "4.2")
+	req.SetPathValue("bool", "true")
+
+	if v, err := PathValue[string](req, "str"); err != nil || v != "abc" {
+		t.Errorf("Unexpected string path value: %q, %v", v, err)
+	}
+	if v, err := PathValue[int](req, "int"); err != nil || v != -42 {
+		t.Errorf("Unexpected int path value: %d, %v", v, err)
+	}
+	if v, err := PathValue[int64](req, "int"); err != nil || v != -42 {
+		t.Errorf("Unexpected int64 path value: %d, %v", v, err)
+	}
+	if v, err := PathValue[uint](req, "uint"); err != nil || v != 42 {
+		t.Errorf("Unexpected uint path value: %d, %v", v, err)
+	}
+	if v, err := PathValue[uint64](req, "uint"); err != nil || v != 42 {
+		t.Errorf("Unexpected uint64 path value: %d, %v", v, err)
+	}
+	if v, err := PathValue[float64](req, "float"); err != nil || v != 4.2 {
+		t.Errorf("Unexpected float64 path value: %f, %v", v, err)
+	}
+	if v, err := PathValue[bool](req, "bool"); err != nil || !v {
+		t.Errorf("Unexpected bool path value: %t, %v", v, err)
+	}
+
+	v, err := PathValue[uint](req, "int")
+	assertInt(t, 0, int(v))
+	assertInt(t, http.StatusBadRequest, statusCode(err))
+	assertString(t, `invalid path value "int": expected uint`, PublicMessage(err))
+
+	_, err = PathValue[string](req, "missing")
+	assertInt(t, http.StatusBadRequest, statusCode(err))
+	assertString(t, `missing path value "missing"`, PublicMessage(err))
+}
@@ -0,0 +1,316 @@
+package ehttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Common errors, one per 4xx/5xx http status code.
+// As two ehttp errors match when they carry the same http status code,
+// they can be used as errors.Is targets.
+var (
+	BadRequest                   = newStatusError(http.StatusBadRequest)
+	Unauthorized                 = newStatusError(http.StatusUnauthorized)
+	PaymentRequired              = newStatusError(http.StatusPaymentRequired)
+	Forbidden                    = newStatusError(http.StatusForbidden)
+	NotFound                     = newStatusError(http.StatusNotFound)
+	MethodNotAllowed             = newStatusError(http.StatusMethodNotAllowed)
+	NotAcceptable                = newStatusError(http.StatusNotAcceptable)
+	ProxyAuthRequired            = newStatusError(http.StatusProxyAuthRequired)
+	RequestTimeout               = newStatusError(http.StatusRequestTimeout)
+	Conflict                     = newStatusError(http.StatusConflict)
+	Gone                         = newStatusError(http.StatusGone)
+	LengthRequired               = newStatusError(http.StatusLengthRequired)
+	PreconditionFailed           = newStatusError(http.StatusPreconditionFailed)
+	RequestEntityTooLarge        = newStatusError(http.StatusRequestEntityTooLarge)
+	RequestURITooLong            = newStatusError(http.StatusRequestURITooLong)
+	UnsupportedMediaType         = newStatusError(http.StatusUnsupportedMediaType)
+	RequestedRangeNotSatisfiable = newStatusError(http.StatusRequestedRangeNotSatisfiable)
+	ExpectationFailed            = newStatusError(http.StatusExpectationFailed)
+	Teapot                       = newStatusError(http.StatusTeapot)
+	MisdirectedRequest           = newStatusError(http.StatusMisdirectedRequest)
+	UnprocessableEntity          = newStatusError(http.StatusUnprocessableEntity)
+	Locked                       = newStatusError(http.StatusLocked)
+	FailedDependency             = newStatusError(http.StatusFailedDependency)
+	TooEarly                     = newStatusError(http.StatusTooEarly)
+	UpgradeRequired              = newStatusError(http.StatusUpgradeRequired)
+	PreconditionRequired         = newStatusError(http.StatusPreconditionRequired)
+	TooManyRequests              = newStatusError(http.StatusTooManyRequests)
+	RequestHeaderFieldsTooLarge  = newStatusError(http.StatusRequestHeaderFieldsTooLarge)
+	UnavailableForLegalReasons   = newStatusError(http.StatusUnavailableForLegalReasons)
+
+	InternalServerError           = newStatusError(http.StatusInternalServerError)
+	NotImplemented                = newStatusError(http.StatusNotImplemented)
+	BadGateway                    = newStatusError(http.StatusBadGateway)
+	ServiceUnavailable            = newStatusError(http.StatusServiceUnavailable)
+	GatewayTimeout                = newStatusError(http.StatusGatewayTimeout)
+	HTTPVersionNotSupported       = newStatusError(http.StatusHTTPVersionNotSupported)
+	VariantAlsoNegotiates         = newStatusError(http.StatusVariantAlsoNegotiates)
+	InsufficientStorage           = newStatusError(http.StatusInsufficientStorage)
+	LoopDetected                  = newStatusError(http.StatusLoopDetected)
+	NotExtended                   = newStatusError(http.StatusNotExtended)
+	NetworkAuthenticationRequired = newStatusError(http.StatusNetworkAuthenticationRequired)
+)
+
+// StatusClass is the class of the http status codes.
+// Can be used as errors.Is target to match the ehttp errors of the class.
+type StatusClass int
+
+// Status classes.
+const (
+	ClientError StatusClass = 4 // 4xx.
+	ServerError StatusClass = 5 // 5xx.
+)
+
+// Error implements the error interface.
+func (c StatusClass) Error() string {
+	switch c {
+	case ClientError:
+		return "client error"
+	case ServerError:
+		return "server error"
+	default:
+		return fmt.Sprintf("%dxx", int(c))
+	}
+}
+
+// contains reports whether the status code is in the class.
+func (c StatusClass) contains(code int) bool {
+	return code/100 == int(c)
+}
+
+// StatusOf returns the http status code of the error, as sent by HandleError.
+// Errors without status code are http.StatusInternalServerError. Returns 0 if the error is nil.
+func StatusOf(err error) int {
+	if err == nil {
+		return 0
+	}
+	return statusCode(err)
+}
+
+// IsClientError reports whether the error has a 4xx http status code.
+func IsClientError(err error) bool {
+	return ClientError.contains(StatusOf(err))
+}
+
+// IsServerError reports whether the error has a 5xx http status code.
+// Errors without status code are server errors.
+func IsServerError(err error) bool {
+	return ServerError.contains(StatusOf(err))
+}
+
+// newErrorf creates a new http error with the formatted message.
+func newErrorf(code int, format string, args ...interface{}) *Error {
+	return &Error{
+		code:      code,
+		error:     fmt.Errorf(format, args...),
+		formatted: true,
+	}
+}
+
+// statusClassIs reports whether the target is the status class of the given code.
+func statusClassIs(code int, target error) bool {
+	var c StatusClass
+	return errors.As(target, &c) && c.contains(code)
+}
+
+// BadRequestf creates a new http.StatusBadRequest error with the formatted message.
+func BadRequestf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusBadRequest, format, args...)
+}
+
+// Unauthorizedf creates a new http.StatusUnauthorized error with the formatted message.
+func Unauthorizedf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusUnauthorized, format, args...)
+}
+
+// PaymentRequiredf creates a new http.StatusPaymentRequired error with the formatted message.
+func PaymentRequiredf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusPaymentRequired, format, args...)
+}
+
+// Forbiddenf creates a new http.StatusForbidden error with the formatted message.
+func Forbiddenf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusForbidden, format, args...)
+}
+
+// NotFoundf creates a new http.StatusNotFound error with the formatted message.
+func NotFoundf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusNotFound, format, args...)
+}
+
+// MethodNotAllowedf creates a new http.StatusMethodNotAllowed error with the formatted message.
+func MethodNotAllowedf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusMethodNotAllowed, format, args...)
+}
+
+// NotAcceptablef creates a new http.StatusNotAcceptable error with the formatted message.
+func NotAcceptablef(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusNotAcceptable, format, args...)
+}
+
+// ProxyAuthRequiredf creates a new http.StatusProxyAuthRequired error with the formatted message.
+func ProxyAuthRequiredf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusProxyAuthRequired, format, args...)
+}
+
+// RequestTimeoutf creates a new http.StatusRequestTimeout error with the formatted message.
+func RequestTimeoutf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusRequestTimeout, format, args...)
+}
+
+// Conflictf creates a new http.StatusConflict error with the formatted message.
+func Conflictf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusConflict, format, args...)
+}
+
+// Gonef creates a new http.StatusGone error with the formatted message.
+func Gonef(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusGone, format, args...)
+}
+
+// LengthRequiredf creates a new http.StatusLengthRequired error with the formatted message.
+func LengthRequiredf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusLengthRequired, format, args...)
+}
+
+// PreconditionFailedf creates a new http.StatusPreconditionFailed error with the formatted message.
+func PreconditionFailedf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusPreconditionFailed, format, args...)
+}
+
+// RequestEntityTooLargef creates a new http.StatusRequestEntityTooLarge error with the formatted message.
+func RequestEntityTooLargef(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusRequestEntityTooLarge, format, args...)
+}
+
+// RequestURITooLongf creates a new http.StatusRequestURITooLong error with the formatted message.
+func RequestURITooLongf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusRequestURITooLong, format, args...)
+}
+
+// UnsupportedMediaTypef creates a new http.StatusUnsupportedMediaType error with the formatted message.
+func UnsupportedMediaTypef(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusUnsupportedMediaType, format, args...)
+}
+
+// RequestedRangeNotSatisfiablef creates a new http.StatusRequestedRangeNotSatisfiable error with the formatted message.
+func RequestedRangeNotSatisfiablef(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusRequestedRangeNotSatisfiable, format, args...)
+}
+
+// ExpectationFailedf creates a new http.StatusExpectationFailed error with the formatted message.
+func ExpectationFailedf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusExpectationFailed, format, args...)
+}
+
+// Teapotf creates a new http.StatusTeapot error with the formatted message.
+func Teapotf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusTeapot, format, args...)
+}
+
+// MisdirectedRequestf creates a new http.StatusMisdirectedRequest error with the formatted message.
+func MisdirectedRequestf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusMisdirectedRequest, format, args...)
+}
+
+// UnprocessableEntityf creates a new http.StatusUnprocessableEntity error with the formatted message.
+func UnprocessableEntityf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusUnprocessableEntity, format, args...)
+}
+
+// Lockedf creates a new http.StatusLocked error with the formatted message.
+func Lockedf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusLocked, format, args...)
+}
+
+// FailedDependencyf creates a new http.StatusFailedDependency error with the formatted message.
+func FailedDependencyf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusFailedDependency, format, args...)
+}
+
+// TooEarlyf creates a new http.StatusTooEarly error with the formatted message.
+func TooEarlyf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusTooEarly, format, args...)
+}
+
+// UpgradeRequiredf creates a new http.StatusUpgradeRequired error with the formatted message.
+func UpgradeRequiredf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusUpgradeRequired, format, args...)
+}
+
+// PreconditionRequiredf creates a new http.StatusPreconditionRequired error with the formatted message.
+func PreconditionRequiredf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusPreconditionRequired, format, args...)
+}
+
+// TooManyRequestsf creates a new http.StatusTooManyRequests error with the formatted message.
+func TooManyRequestsf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusTooManyRequests, format, args...)
+}
+
+// RequestHeaderFieldsTooLargef creates a new http.StatusRequestHeaderFieldsTooLarge error with the formatted message.
+func RequestHeaderFieldsTooLargef(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusRequestHeaderFieldsTooLarge, format, args...)
+}
+
+// UnavailableForLegalReasonsf creates a new http.StatusUnavailableForLegalReasons error with the formatted message.
+func UnavailableForLegalReasonsf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusUnavailableForLegalReasons, format, args...)
+}
+
+// InternalServerErrorf creates a new http.StatusInternalServerError error with the formatted message.
+func InternalServerErrorf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusInternalServerError, format, args...)
+}
+
+// NotImplementedf creates a new http.StatusNotImplemented error with the formatted message.
+func NotImplementedf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusNotImplemented, format, args...)
+}
+
+// BadGatewayf creates a new http.StatusBadGateway error with the formatted message.
+func BadGatewayf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusBadGateway, format, args...)
+}
+
+// ServiceUnavailablef creates a new http.StatusServiceUnavailable error with the formatted message.
+func ServiceUnavailablef(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusServiceUnavailable, format, args...)
+}
+
+// GatewayTimeoutf creates a new http.StatusGatewayTimeout error with the formatted message.
+func GatewayTimeoutf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusGatewayTimeout, format, args...)
+}
+
+// HTTPVersionNotSupportedf creates a new http.StatusHTTPVersionNotSupported error with the formatted message.
+func HTTPVersionNotSupportedf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusHTTPVersionNotSupported, format, args...)
+}
+
+// VariantAlsoNegotiatesf creates a new http.StatusVariantAlsoNegotiates error with the formatted message.
+func VariantAlsoNegotiatesf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusVariantAlsoNegotiates, format, args...)
+}
+
+// InsufficientStoragef creates a new http.StatusInsufficientStorage error with the formatted message.
+func InsufficientStoragef(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusInsufficientStorage, format, args...)
+}
+
+// LoopDetectedf creates a new http.StatusLoopDetected error with the formatted message.
+func LoopDetectedf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusLoopDetected, format, args...)
+}
+
+// NotExtendedf creates a new http.StatusNotExtended error with the formatted message.
+func NotExtendedf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusNotExtended, format, args...)
+}
+
+// NetworkAuthenticationRequiredf creates a new http.StatusNetworkAuthenticationRequired error with the formatted message.
+func NetworkAuthenticationRequiredf(format string, args ...interface{}) *Error {
+	return newErrorf(http.StatusNetworkAuthenticationRequired, format, args...)
+}
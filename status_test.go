@@ -0,0 +1,53 @@
+package ehttp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestStatusSentinels(t *testing.T) {
+	for _, e := range []*Error{Forbidden, Conflict, TooManyRequests, ServiceUnavailable, NetworkAuthenticationRequired} {
+		assertString(t, http.StatusText(e.Code()), e.Error())
+	}
+	err := Conflictf("user %q already exists", "bob")
+	assertInt(t, http.StatusConflict, err.Code())
+	assertString(t, `user "bob" already exists`, err.Error())
+	if !errors.Is(fmt.Errorf("wrapped: %w", err), Conflict) {
+		t.Error("Conflictf error should match Conflict")
+	}
+	assertInt(t, http.StatusServiceUnavailable, ServiceUnavailablef("down").Code())
+}
+
+func TestStatusClass(t *testing.T) {
+	for _, tc := range []struct {
+		err    error
+		status int
+		client bool
+		server bool
+	}{
+		{err: nil, status: 0},
+		{err: NotFound, status: http.StatusNotFound, client: true},
+		{err: fmt.Errorf("wrapped: %w", TooManyRequestsf("slow down")), status: http.StatusTooManyRequests, client: true},
+		{err: NewValidationError(), status: http.StatusUnprocessableEntity, client: true},
+		{err: BadGateway, status: http.StatusBadGateway, server: true},
+		{err: io.EOF, status: http.StatusInternalServerError, server: true},
+	} {
+		assertInt(t, tc.status, StatusOf(tc.err))
+		if IsClientError(tc.err) != tc.client || errors.Is(tc.err, ClientError) != tc.client {
+			t.Errorf("Unexpected client error class for %v", tc.err)
+		}
+		if IsServerError(tc.err) != tc.server {
+			t.Errorf("Unexpected server error class for %v", tc.err)
+		}
+	}
+	// Only the ehttp errors match the server error class.
+	if errors.Is(io.EOF, ServerError) || !errors.Is(BadGateway, ServerError) {
+		t.Error("Unexpected server error class match")
+	}
+	assertString(t, "client error", ClientError.Error())
+	assertString(t, "server error", ServerError.Error())
+	assertString(t, "3xx", StatusClass(3).Error())
+}
@@ -0,0 +1,118 @@
+package ehttp
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// FieldError describes the validation failure of a single field.
+type FieldError struct {
+	Field   string `json:"field"`   // Path of the field, e.g. "address.zip" or "items[0].name".
+	Code    string `json:"code"`    // Machine-readable failure code, e.g. "required".
+	Message string `json:"message"` // Human-readable message.
+}
+
+// ValidationError is an error holding the per-field validation failures.
+// Sent with the Status http status code, http.StatusUnprocessableEntity if not set.
+// The default JSON and problem details encoders send the fields as structured data.
+type ValidationError struct {
+	Status int
+	Fields []FieldError
+}
+
+// NewValidationError creates a new ValidationError with the given fields.
+func NewValidationError(fields ...FieldError) *ValidationError {
+	return &ValidationError{Fields: fields}
+}
+
+// Add appends a field failure to the error.
+func (e *ValidationError) Add(field, code, message string) *ValidationError {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: message})
+	return e
+}
+
+// Err returns the error if it has at least one field failure, nil otherwise.
+// Convenient to build the error from a Validate method:
+//
+//	func (r Request) Validate() error {
+//		verr := &ehttp.ValidationError{}
+//		if r.Name == "" {
+//			verr.Add("name", "required", "name is required")
+//		}
+//		return verr.Err()
+//	}
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// StatusCode returns the http status code of the error. Implements StatusCoder.
+func (e *ValidationError) StatusCode() int {
+	if e.Status == 0 {
+		return http.StatusUnprocessableEntity
+	}
+	return e.Status
+}
+
+// Is reports whether the target is the StatusClass of the error http status code.
+func (e *ValidationError) Is(target error) bool {
+	return statusClassIs(e.StatusCode(), target)
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+": "+f.Message)
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// Validator can be implemented by the request types to be validated by DecodeJSON.
+// Validate should return a *ValidationError or an ehttp error, the other errors are sent
+// with http.StatusUnprocessableEntity and their message.
+type Validator interface {
+	Validate() error
+}
+
+// validate calls the Validate method of v if it implements Validator.
+// v being the pointer given to DecodeJSON, the non-nil value it points to is validated as well,
+// i.e. *T when v is a **T.
+func validate(v interface{}) error {
+	vv, ok := v.(Validator)
+	if !ok {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Pointer || rv.IsNil() {
+			return nil
+		}
+		if elem := rv.Elem(); elem.Kind() == reflect.Pointer && !elem.IsNil() {
+			vv, ok = elem.Interface().(Validator)
+		}
+		if !ok {
+			return nil
+		}
+	}
+	err := vv.Validate()
+	if err == nil {
+		return nil
+	}
+	var e1 *Error
+	var sc StatusCoder
+	if errors.As(err, &e1) || errors.As(err, &sc) {
+		return err
+	}
+	return newErrorf(http.StatusUnprocessableEntity, "%w", err)
+}
+
+// fieldErrors returns the field failures of the first ValidationError in the error chain.
+func fieldErrors(err error) []FieldError {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return ve.Fields
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package ehttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type validatedRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func (r validatedRequest) Validate() error {
+	if r.Name == "invalid" {
+		return errors.New("invalid request")
+	}
+	verr := &ValidationError{}
+	if r.Name == "" {
+		verr.Add("name", "required", "name is required")
+	}
+	if !strings.Contains(r.Email, "@") {
+		verr.Add("email", "format", "invalid email")
+	}
+	return verr.Err()
+}
+
+func TestValidationError(t *testing.T) {
+	verr := NewValidationError(FieldError{Field: "name", Code: "required", Message: "name is required"})
+	assertInt(t, http.StatusUnprocessableEntity, statusCode(verr))
+	assertString(t, "validation failed: name: name is required", PublicMessage(verr))
+
+	verr.Status = http.StatusBadRequest
+	assertInt(t, http.StatusBadRequest, statusCode(fmt.Errorf("wrapped: %w", verr)))
+
+	// An explicit status takes precedence.
+	assertInt(t, http.StatusConflict, statusCode(NewError(http.StatusConflict, verr)))
+
+	if err := (&ValidationError{}).Err(); err != nil {
+		t.Fatalf("Empty validation error should be nil, got: %v", err)
+	}
+}
+
+func TestValidationEncoders(t *testing.T) {
+	verr := NewValidationError().Add("items[0].name", "required", "name is required")
+
+	rec := httptest.NewRecorder()
+	EncodeJSON(NewResponseWriter(rec), nil, verr)
+	assertString(t, `{"errors":["validation failed: items[0].name: name is required"],"fields":[{"field":"items[0].name","code":"required","message":"name is required"}]}`+"\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	EncodeJSON(NewResponseWriter(rec), nil, NotFound)
+	assertString(t, `{"errors":["Not Found"]}`+"\n", rec.Body.String())
+
+	ext := NotFound.WithExtension("id", "42")
+	p := NewProblem(nil, http.StatusUnprocessableEntity, NewError(http.StatusUnprocessableEntity, verr))
+	buf, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertString(t, `{"detail":"validation failed: items[0].name: name is required","fields":[{"field":"items[0].name","code":"required","message":"name is required"}],"status":422,"title":"Unprocessable Entity"}`, string(buf))
+	// Make sure the error extensions are not altered.
+	_ = NewProblem(nil, http.StatusNotFound, errors.Join(ext, verr))
+	assertInt(t, 1, len(ext.Extensions()))
+}
+
+func TestDecodeJSONValidator(t *testing.T) {
+	mux := New()
+	mux.Post("/", JSON(func(ctx context.Context, req validatedRequest) (validatedRequest, error) {
+		return req, nil
+	}))
+
+	for _, tc := range []struct {
+		body   string
+		status int
+		expect string
+	}{
+		{body: `{"name":"bob","email":"bob@example.com"}`, status: http.StatusOK, expect: `{"name":"bob","email":"bob@example.com"}`},
+		{body: `{"email":"bob"}`, status: http.StatusUnprocessableEntity, expect: `{"errors":["validation failed: name: name is required; email: invalid email"],"fields":[{"field":"name","code":"required","message":"name is required"},{"field":"email","code":"format","message":"invalid email"}]}`},
+		{body: `{"name":"invalid"}`, status: http.StatusUnprocessableEntity, expect: `{"errors":["invalid request"]}`},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(tc.body)))
+		assertInt(t, tc.status, rec.Code)
+		assertString(t, tc.expect+"\n", rec.Body.String())
+	}
+}
+
+type validatedPointerRequest struct {
+	Name string `json:"name"`
+}
+
+func (r *validatedPointerRequest) Validate() error {
+	if r.Name == "" {
+		return NewValidationError(FieldError{Field: "name", Code: "required", Message: "name is required"})
+	}
+	return nil
+}
+
+func TestDecodeJSONValidatorPointer(t *testing.T) {
+	mux := New()
+	mux.Post("/", JSON(func(ctx context.Context, req *validatedPointerRequest) (*validatedPointerRequest, error) {
+		if req == nil {
+			t.Error("The request should not be nil")
+		}
+		return req, nil
+	}))
+	mux.Post("/value", JSON(func(ctx context.Context, req *validatedRequest) (*validatedRequest, error) {
+		return req, nil
+	}))
+
+	for _, tc := range []struct {
+		path   string
+		body   string
+		status int
+	}{
+		{path: "/", body: `{"name":"bob"}`, status: http.StatusOK},
+		{path: "/", body: `{}`, status: http.StatusUnprocessableEntity},
+		{path: "/", body: ``, status: http.StatusUnprocessableEntity},
+		{path: "/", body: `null`, status: http.StatusUnprocessableEntity},
+		{path: "/value", body: `{"name":"bob","email":"bob@example.com"}`, status: http.StatusOK},
+		{path: "/value", body: `{"name":"invalid"}`, status: http.StatusUnprocessableEntity},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("POST", tc.path, strings.NewReader(tc.body)))
+		assertInt(t, tc.status, rec.Code)
+	}
+
+	var req *validatedPointerRequest
+	err := DecodeJSON(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(`{}`)), &req)
+	assertInt(t, http.StatusUnprocessableEntity, StatusOf(err))
+}